@@ -17,35 +17,293 @@ import (
 )
 
 // Summary commands
+
+// newGenerator creates a changelog generator wired to the project config
+func (m model) newGenerator() *changelog.Generator {
+	gen := changelog.NewGenerator(m.git, m.meta)
+	gen.Config = m.cfg
+	gen.Layout = m.summaryLayout
+	gen.Version = version
+	// only real repositories are cached, the demo history is made up
+	if _, ok := m.git.(*git.Runner); ok {
+		gen.CachePath = filepath.Join(".tutugit", "cache", "releases.json")
+	}
+	if m.summaryPackage != "" {
+		if scoped, err := gen.ForPackage(m.summaryPackage); err == nil {
+			return scoped
+		}
+	}
+	return gen
+}
+
+// currentLayout returns the layout of the summary: the one picked in the view, else the configured one
+func (m model) currentLayout() string {
+	if m.summaryLayout != "" {
+		return m.summaryLayout
+	}
+	if m.cfg != nil && m.cfg.Changelog.Layout != "" {
+		return m.cfg.Changelog.Layout
+	}
+	return changelog.LayoutTags
+}
+
 func (m model) fetchSummary() tea.Cmd {
 	return func() tea.Msg {
 		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 		defer cancel()
-		gen := changelog.NewGenerator(m.git, m.meta)
-		rels, err := gen.GenerateFull(ctx)
+		gen := m.newGenerator()
+		rels, err := m.generateSummary(ctx, gen)
 		if err != nil {
 			return errMsg(err)
 		}
-		content := gen.FormatSummary(rels)
-		return summaryMsg(content)
+		templates, err := changelog.LoadTemplates(filepath.Join(".tutugit", "templates"))
+		if err != nil {
+			return errMsg(err)
+		}
+		return summaryMsg{releases: rels, tmpl: templates[changelog.SummaryTemplate]}
+	}
+}
+
+// generateSummary generates the releases of the summary: the whole history, or the range picked in the view
+func (m model) generateSummary(ctx context.Context, gen *changelog.Generator) ([]*changelog.Release, error) {
+	if !m.hasSummaryRange() {
+		return gen.GenerateFull(ctx)
+	}
+	head := m.summaryHead
+	if head.rev == "" {
+		head = summaryRef{rev: "HEAD", name: "HEAD"}
+	}
+	rel, err := gen.GenerateRelease(ctx, head.name, m.summaryBase.rev, head.rev)
+	if err != nil {
+		return nil, err
+	}
+	return []*changelog.Release{rel}, nil
+}
+
+// hasSummaryRange reports whether a range was picked in the summary view
+func (m model) hasSummaryRange() bool {
+	return m.summaryBase.rev != "" || m.summaryHead.rev != ""
+}
+
+// filteredSummary generates the releases shown in the summary view, range and filters applied
+func (m model) filteredSummary(ctx context.Context, gen *changelog.Generator) ([]*changelog.Release, error) {
+	rels, err := m.generateSummary(ctx, gen)
+	if err != nil {
+		return nil, err
+	}
+	return m.summaryFilter.Apply(rels), nil
+}
+
+// fetchReleasePlan computes the next version on the channel of the release screen
+func (m model) fetchReleasePlan() tea.Msg {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	plan, err := m.newGenerator().PlanRelease(ctx, m.releaseChannel)
+	return releasePlanMsg{plan: plan, err: err}
+}
+
+// createRelease tags the planned release and records it in meta.json. When recording fails
+// the tag stays, and [R] in the summary view records it later.
+func (m model) createRelease(plan *changelog.VersionPlan) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+		if err := tagRelease(ctx, m.git, plan); err != nil {
+			return errMsg(err)
+		}
+		if _, err := recordVersion(ctx, m.newGenerator(), m.wsManager, plan.Tag, true); err != nil {
+			return errMsg(fmt.Errorf("%s is tagged but could not be recorded in meta.json: %w", plan.Tag, err))
+		}
+		return successMsg(fmt.Sprintf("Tagged %s!", plan.Tag))
 	}
 }
 
+// fetchSummaryRefs lists the revisions the range picker offers: tags, branches and recent commits
+func (m model) fetchSummaryRefs() tea.Msg {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var refs []summaryRef
+	tags, err := m.git.GetTags(ctx)
+	if err != nil {
+		return errMsg(err)
+	}
+	for _, t := range tags {
+		refs = append(refs, summaryRef{rev: t, name: t, label: "tag     " + t})
+	}
+	branches, err := m.git.GetBranches(ctx)
+	if err != nil {
+		return errMsg(err)
+	}
+	for _, b := range branches {
+		refs = append(refs, summaryRef{rev: b, name: b, label: "branch  " + b})
+	}
+	commits, err := m.git.GetLog(ctx, defaultHistoryLimit)
+	if err != nil {
+		return errMsg(err)
+	}
+	for _, c := range commits {
+		refs = append(refs, summaryRef{rev: c.Hash, name: c.ShortHash, label: "commit  " + c.ShortHash + " " + c.Message})
+	}
+	return summaryRefsMsg(refs)
+}
+
+// exportMarkdown writes the releases of the summary view to .tutugit/release.md, plus one file
+// per custom template in .tutugit/templates/
 func (m model) exportMarkdown() tea.Cmd {
 	return func() tea.Msg {
 		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 		defer cancel()
-		gen := changelog.NewGenerator(m.git, m.meta)
+		gen := m.newGenerator()
+		rels, err := m.filteredSummary(ctx, gen)
+		if err != nil {
+			return errMsg(err)
+		}
+		templates, err := changelog.LoadTemplates(filepath.Join(".tutugit", "templates"))
+		if err != nil {
+			return errMsg(err)
+		}
+
+		if _, ok := templates[changelog.MarkdownTemplate]; !ok {
+			data := gen.ExportMarkdown(rels)
+			path := filepath.Join(".tutugit", changelog.MarkdownTemplate)
+			if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+				return errMsg(err)
+			}
+		}
+		written := 1
+		for name, tmpl := range templates {
+			if name == changelog.SummaryTemplate {
+				continue // only used by the summary view
+			}
+			data, err := gen.Render(tmpl, rels)
+			if err != nil {
+				return errMsg(fmt.Errorf("template %s: %w", name, err))
+			}
+			if err := os.WriteFile(filepath.Join(".tutugit", name), []byte(data), 0644); err != nil {
+				return errMsg(err)
+			}
+			if name != changelog.MarkdownTemplate {
+				written++
+			}
+		}
+		if written > 1 {
+			return successMsg(fmt.Sprintf("Exported %d files to .tutugit/!", written))
+		}
+		return successMsg("Exported to .tutugit/release.md!")
+	}
+}
+
+// currentExportFormat returns the format E writes in the summary view
+func (m model) currentExportFormat() string {
+	if m.exportFormat != "" {
+		return m.exportFormat
+	}
+	return changelog.FormatMarkdown
+}
+
+// renderExport renders releases in an export format. A custom template named after the
+// export file (e.g. release.html.tmpl) replaces the built-in layout; the Atom entries follow
+// the HTML one.
+func renderExport(gen *changelog.Generator, rels []*changelog.Release, format string) ([]byte, error) {
+	templates, err := changelog.LoadTemplates(filepath.Join(".tutugit", "templates"))
+	if err != nil {
+		return nil, err
+	}
+	if tmpl, ok := templates[changelog.ExportFile(format)]; ok {
+		out, err := gen.Render(tmpl, rels)
+		return []byte(out), err
+	}
+	if strings.EqualFold(format, changelog.FormatAtom) {
+		return gen.ExportAtomWith(templates[changelog.ExportFile(changelog.FormatHTML)], rels)
+	}
+	return gen.Export(rels, format)
+}
+
+// exportSummary writes the releases of the summary view to .tutugit/ in one of the export formats
+func (m model) exportSummary(format string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+		gen := m.newGenerator()
+		rels, err := m.filteredSummary(ctx, gen)
+		if err != nil {
+			return errMsg(err)
+		}
+		data, err := renderExport(gen, rels, format)
+		if err != nil {
+			return errMsg(err)
+		}
+		path := filepath.Join(".tutugit", changelog.ExportFile(format))
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return errMsg(err)
+		}
+		return successMsg(fmt.Sprintf("Exported to %s!", path))
+	}
+}
+
+// writeChangelog inserts the latest tagged release into CHANGELOG.md (Keep a Changelog format),
+// or into the changelog of the package picked in the summary
+func (m model) writeChangelog() tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+		gen := m.newGenerator()
 		rels, err := gen.GenerateFull(ctx)
 		if err != nil {
 			return errMsg(err)
 		}
-		data := gen.ExportMarkdown(rels)
-		path := filepath.Join(".tutugit", "release.md")
+
+		var latest *changelog.Release
+		for _, rel := range rels {
+			if rel.Version != changelog.UnreleasedVersion {
+				latest = rel
+				break
+			}
+		}
+		if latest == nil {
+			return errMsg(fmt.Errorf("no tagged release to write (create a tag first)"))
+		}
+
+		path := gen.ChangelogFile()
+		existing, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return errMsg(err)
+		}
+		data := gen.UpdateKeepAChangelog(ctx, string(existing), latest)
 		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
 			return errMsg(err)
 		}
-		return successMsg("Exported to .tutugit/release.md!")
+		return successMsg(fmt.Sprintf("Wrote %s to %s!", latest.Version, path))
+	}
+}
+
+// setUpcomingNotes stores the intro and highlights of the next release in meta.json
+func (m model) setUpcomingNotes(notes workspace.ReleaseNotes) tea.Cmd {
+	return func() tea.Msg {
+		if err := m.wsManager.SetUpcomingNotes(notes); err != nil {
+			return errMsg(err)
+		}
+		return nil
+	}
+}
+
+// recordRelease freezes a published release in meta.json, so it keeps its notes whatever
+// happens to the tags and metadata afterwards
+func (m model) recordRelease(version string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+		if version == changelog.UnreleasedVersion {
+			return errMsg(fmt.Errorf("unreleased changes can't be recorded (create a tag first)"))
+		}
+		recorded, err := recordVersion(ctx, m.newGenerator(), m.wsManager, version, false)
+		if err != nil {
+			return errMsg(err)
+		}
+		if recorded {
+			return successMsg(fmt.Sprintf("%s is already recorded.", version))
+		}
+		return successMsg(fmt.Sprintf("Recorded %s in meta.json!", version))
 	}
 }
 
@@ -120,6 +378,34 @@ func (m model) fetchMeta() tea.Msg {
 	return metaMsg(meta)
 }
 
+// toggleHidden hides a commit from the changelog, or shows it again, and reloads the
+// metadata so the history view stays where it is.
+func (m model) toggleHidden(hash string) tea.Cmd {
+	hidden := m.meta == nil || !m.meta.Hidden[hash]
+	return func() tea.Msg {
+		if err := m.wsManager.SetHidden(hash, hidden); err != nil {
+			return errMsg(err)
+		}
+		return m.fetchMeta()
+	}
+}
+
+// setChangelogText stores the changelog subject and description of a commit. A subject
+// equal to the commit message is not an override.
+func (m model) setChangelogText(hash, subject, description string) tea.Cmd {
+	for _, c := range m.commits {
+		if c.Hash == hash && c.Message == strings.TrimSpace(subject) {
+			subject = ""
+		}
+	}
+	return func() tea.Msg {
+		if err := m.wsManager.SetChangelogText(hash, strings.TrimSpace(subject), strings.TrimSpace(description)); err != nil {
+			return errMsg(err)
+		}
+		return m.fetchMeta()
+	}
+}
+
 func (m model) fetchHygiene() tea.Msg {
 	report, err := m.hygiene.GetReport(context.Background())
 	if err != nil {
@@ -199,6 +485,13 @@ func (m model) doCommit(msg string) tea.Cmd {
 			}
 		}
 
+		// Record conventional commit scope
+		if scope := workspace.DetectScope(msg); scope != "" {
+			if err := m.wsManager.AddScope(hash, scope); err != nil {
+				return errMsg(err)
+			}
+		}
+
 		// Auto-assign to active workspace
 		if m.meta != nil && m.meta.ActiveWorkspace != "" {
 			if err := m.wsManager.AddCommitToWorkspace(m.meta.ActiveWorkspace, hash); err != nil {
@@ -211,6 +504,13 @@ func (m model) doCommit(msg string) tea.Cmd {
 			return errMsg(err)
 		}
 
+		// Save migration notes of breaking changes
+		if notes := strings.TrimSpace(m.migrationNotes.Value()); m.decidedImpact == "major" && notes != "" {
+			if err := m.wsManager.AddBreakingNote(hash, notes); err != nil {
+				return errMsg(err)
+			}
+		}
+
 		return successMsg("Commit done!")
 	}
 }
@@ -16,12 +16,16 @@ const (
 	stateHunks
 	stateDiff
 	stateHistory
+	stateChangelogText
 	stateReflog
 	stateReflogConfirm
 	stateGitWorktrees
 	stateRebasePrepare
 	stateRebaseOngoing
 	stateSummary
+	stateSummaryRange
+	stateReleaseNotes
+	stateRelease
 )
 
 // UI Constants
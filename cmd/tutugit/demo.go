@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"time"
 	"tutugit/internal/config"
 	"tutugit/internal/git"
 	"tutugit/internal/hygiene"
@@ -15,15 +16,17 @@ func initialDemoModel() model {
 	mock := git.NewMockRunner()
 
 	// mock History
+	now := time.Now()
+	ago := func(d time.Duration) time.Time { return now.Add(-d) }
 	mock.Commits = []git.Commit{
-		{Hash: "sha7", ShortHash: "abc777", Message: "docs: update API documentation", Author: "Alice", Date: "2 mins ago", Email: "alice@example.com"},
-		{Hash: "sha6", ShortHash: "abc666", Message: "fix: prevent memory leak in workspace manager", Author: "Bob", Date: "5 mins ago", Email: "bob@example.com"},
-		{Hash: "sha5", ShortHash: "abc555", Message: "feat: add demo mode toggle", Author: "Carlos", Date: "10 mins ago", Email: "carlos@example.com"},
-		{Hash: "sha4", ShortHash: "abc444", Message: "wip: incomplete work on styles", Author: "Dev", Date: "1 hour ago", Email: "dev@example.com"},
-		{Hash: "sha3", ShortHash: "abc333", Message: "refactor: clean up viewport logic", Author: "Carlos", Date: "5 hours ago", Email: "carlos@example.com"},
-		{Hash: "sha2", ShortHash: "abc222", Message: "fix: resolve scary panic in update.go", Author: "John", Date: "1 day ago", Email: "john@example.com"},
-		{Hash: "sha1", ShortHash: "abc111", Message: "feat: add super cool workspace grouping", Author: "Carlos", Date: "2 days ago", Email: "carlos@example.com"},
-		{Hash: "sha0", ShortHash: "abc000", Message: "chore: initial commit", Author: "Carlos", Date: "1 week ago", Email: "carlos@example.com"},
+		{Hash: "sha7", ShortHash: "abc777", Message: "docs: update API documentation", Author: "Alice", AuthorDate: ago(2 * time.Minute), CommitterDate: ago(2 * time.Minute), Email: "alice@example.com"},
+		{Hash: "sha6", ShortHash: "abc666", Message: "fix: prevent memory leak in workspace manager", Author: "Bob", AuthorDate: ago(5 * time.Minute), CommitterDate: ago(5 * time.Minute), Email: "bob@example.com"},
+		{Hash: "sha5", ShortHash: "abc555", Message: "feat: add demo mode toggle", Author: "Carlos", AuthorDate: ago(10 * time.Minute), CommitterDate: ago(10 * time.Minute), Email: "carlos@example.com"},
+		{Hash: "sha4", ShortHash: "abc444", Message: "wip: incomplete work on styles", Author: "Dev", AuthorDate: ago(time.Hour), CommitterDate: ago(time.Hour), Email: "dev@example.com"},
+		{Hash: "sha3", ShortHash: "abc333", Message: "refactor: clean up viewport logic", Author: "Carlos", AuthorDate: ago(5 * time.Hour), CommitterDate: ago(5 * time.Hour), Email: "carlos@example.com"},
+		{Hash: "sha2", ShortHash: "abc222", Message: "fix: resolve scary panic in update.go", Author: "John", AuthorDate: ago(24 * time.Hour), CommitterDate: ago(24 * time.Hour), Email: "john@example.com"},
+		{Hash: "sha1", ShortHash: "abc111", Message: "feat: add super cool workspace grouping", Author: "Carlos", AuthorDate: ago(48 * time.Hour), CommitterDate: ago(48 * time.Hour), Email: "carlos@example.com"},
+		{Hash: "sha0", ShortHash: "abc000", Message: "chore: initial commit", Author: "Carlos", AuthorDate: ago(7 * 24 * time.Hour), CommitterDate: ago(7 * 24 * time.Hour), Email: "carlos@example.com"},
 	}
 	mock.ValidHashes = map[string]bool{
 		"sha7": true, "sha6": true, "sha5": true, "sha4": true, "sha3": true, "sha2": true, "sha1": true, "sha0": true,
@@ -72,6 +75,8 @@ func initialDemoModel() model {
 		{Path: "/tmp/tutugit-fix", Branch: "hotfix/emergency", Hash: "sha2", IsMain: false},
 	}
 
+	mock.Branches = []string{"feature/demo-mode", "hotfix/emergency", "main"}
+
 	// mock Rebase state
 	mock.IsRebasingVal = true
 	mock.RebaseTodo = []git.RebaseStep{
@@ -108,8 +113,12 @@ func initialDemoModel() model {
 
 	ti := textinput.New()
 	ti.Placeholder = "Demo commit..."
+	mn := textinput.New()
+	mn.Placeholder = "Demo migration notes..."
 	wn := textinput.New()
 	wd := textinput.New()
+	ss := textinput.New()
+	ss.Prompt = "/"
 
 	vp := newStyledViewport("62")
 	hp := newStyledViewport("63")
@@ -132,13 +141,19 @@ func initialDemoModel() model {
 		isRebasing:      true,
 		state:           stateMain,
 		commitMsg:       ti,
+		migrationNotes:  mn,
 		newWsName:       wn,
 		newWsDesc:       wd,
+		entrySubject:    textinput.New(),
+		entryDesc:       newEntryDescArea(),
 		expandedFile:    -1,
 		diffViewport:    vp,
 		historyViewport: hp,
 		reflogViewport:  rp,
 		summaryViewport: sp,
+		summarySearch:   ss,
+		releaseNotes:    newReleaseNotesArea(),
+		collapsed:       make(map[string]bool),
 		expandedHistory: make(map[string]bool),
 		isUpdating:      false,
 		decidedImpact:   "patch",
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"tutugit/internal/changelog"
+	"tutugit/internal/config"
+	"tutugit/internal/git"
+	"tutugit/internal/workspace"
+)
+
+// exportUsage is printed when "tutugit export" gets arguments it doesn't understand
+const exportUsage = "usage: tutugit export [markdown|json|html|atom] [--package name] [-o file]"
+
+// exportOptions are the arguments of "tutugit export"
+type exportOptions struct {
+	format string
+	output string // "" for the default file, "-" for stdout
+	pkg    string // monorepo package to export, "" for the whole repository
+}
+
+// exportArgs parses "[format] [--package name] [-o file]"
+func exportArgs(args []string) (exportOptions, error) {
+	opts := exportOptions{format: changelog.FormatMarkdown}
+	formatSet := false
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; {
+		case arg == "-o" || arg == "--output":
+			if i+1 >= len(args) {
+				return opts, fmt.Errorf("%s needs a file name\n%s", arg, exportUsage)
+			}
+			i++
+			opts.output = args[i]
+		case arg == "-p" || arg == "--package":
+			if i+1 >= len(args) {
+				return opts, fmt.Errorf("%s needs a package name\n%s", arg, exportUsage)
+			}
+			i++
+			opts.pkg = args[i]
+		case !formatSet && !strings.HasPrefix(arg, "-"):
+			opts.format, formatSet = strings.ToLower(arg), true
+		default:
+			return opts, fmt.Errorf("unexpected argument %q\n%s", arg, exportUsage)
+		}
+	}
+	if opts.format == "md" {
+		opts.format = changelog.FormatMarkdown
+	}
+	if changelog.ExportFile(opts.format) == "" {
+		return opts, fmt.Errorf("unknown export format %q\n%s", opts.format, exportUsage)
+	}
+	return opts, nil
+}
+
+// runExport renders the release history of the repository in the working directory
+func runExport(args []string) error {
+	opts, err := exportArgs(args)
+	if err != nil {
+		return err
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+	cfg, err := config.NewManager(cwd).Load()
+	if err != nil {
+		return err
+	}
+	meta, err := workspace.NewManager(cwd).Load()
+	if err != nil {
+		return err
+	}
+
+	m := model{git: git.NewRunner(cwd), cfg: cfg, meta: meta}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	gen := m.newGenerator()
+	if opts.pkg != "" {
+		if gen, err = gen.ForPackage(opts.pkg); err != nil {
+			return err
+		}
+	}
+	rels, err := gen.GenerateFull(ctx)
+	if err != nil {
+		return err
+	}
+	data, err := renderExport(gen, rels, opts.format)
+	if err != nil {
+		return err
+	}
+
+	output := opts.output
+	switch output {
+	case "-":
+		_, err = os.Stdout.Write(data)
+		return err
+	case "":
+		output = filepath.Join(".tutugit", changelog.ExportFile(opts.format))
+		if err := os.MkdirAll(".tutugit", 0755); err != nil {
+			return err
+		}
+	}
+	if err := os.WriteFile(output, data, 0644); err != nil {
+		return err
+	}
+	fmt.Printf("Exported to %s\n", output)
+	return nil
+}
@@ -1,7 +1,20 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"tutugit/internal/changelog"
+	"tutugit/internal/config"
+	"tutugit/internal/git"
+	"tutugit/internal/workspace"
+
+	tea "github.com/charmbracelet/bubbletea"
 )
 
 func TestVersion(t *testing.T) {
@@ -21,12 +34,16 @@ func TestStateConstants(t *testing.T) {
 		stateWorkspaces,
 		stateNewWorkspace,
 		stateHistory,
+		stateChangelogText,
 		stateReflog,
 		stateReflogConfirm,
 		stateRebasePrepare,
 		stateRebaseOngoing,
 		stateGitWorktrees,
 		stateSummary,
+		stateSummaryRange,
+		stateReleaseNotes,
+		stateRelease,
 	}
 
 	seen := make(map[state]bool)
@@ -73,3 +90,415 @@ func safeGet(slice []string, index int) string {
 	}
 	return slice[index]
 }
+
+func TestRelativeTime(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		ago      time.Duration
+		expected string
+	}{
+		{30 * time.Second, "30 seconds ago"},
+		{time.Minute, "1 minute ago"},
+		{5 * time.Hour, "5 hours ago"},
+		{3 * 24 * time.Hour, "3 days ago"},
+		{21 * 24 * time.Hour, "3 weeks ago"},
+		{90 * 24 * time.Hour, "3 months ago"},
+		{800 * 24 * time.Hour, "2 years ago"},
+	}
+
+	for _, tt := range tests {
+		if got := relativeTime(now.Add(-tt.ago), now); got != tt.expected {
+			t.Errorf("relativeTime(-%v) = %q; want %q", tt.ago, got, tt.expected)
+		}
+	}
+	if got := relativeTime(time.Time{}, now); got != "unknown date" {
+		t.Errorf("zero time = %q", got)
+	}
+}
+
+func TestExportArgs(t *testing.T) {
+	tests := []struct {
+		args    []string
+		want    exportOptions
+		wantErr bool
+	}{
+		{args: nil, want: exportOptions{format: "markdown"}},
+		{args: []string{"html"}, want: exportOptions{format: "html"}},
+		{args: []string{"MD", "-o", "-"}, want: exportOptions{format: "markdown", output: "-"}},
+		{args: []string{"-o", "feed.xml", "atom"}, want: exportOptions{format: "atom", output: "feed.xml"}},
+		{args: []string{"json", "--package", "api"}, want: exportOptions{format: "json", pkg: "api"}},
+		{args: []string{"pdf"}, wantErr: true},
+		{args: []string{"json", "-o"}, wantErr: true},
+		{args: []string{"json", "-p"}, wantErr: true},
+		{args: []string{"json", "html"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		opts, err := exportArgs(tt.args)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("exportArgs(%v) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && opts != tt.want {
+			t.Errorf("exportArgs(%v) = %+v; want %+v", tt.args, opts, tt.want)
+		}
+	}
+}
+
+func TestReleaseArgs(t *testing.T) {
+	tests := []struct {
+		args    []string
+		want    releaseOptions
+		wantErr bool
+	}{
+		{args: nil, want: releaseOptions{}},
+		{args: []string{"--channel", "beta", "-n"}, want: releaseOptions{channel: "beta", channelSet: true, dryRun: true}},
+		{args: []string{"--final"}, want: releaseOptions{channelSet: true}},
+		{args: []string{"--confirm-major"}, want: releaseOptions{confirm: true}},
+		{args: []string{"-p", "api", "--final"}, want: releaseOptions{pkg: "api", channelSet: true}},
+		{args: []string{"--package"}, wantErr: true},
+		{args: []string{"--channel"}, wantErr: true},
+		{args: []string{"--channel", "b.1"}, wantErr: true},
+		{args: []string{"now"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		opts, err := releaseArgs(tt.args)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("releaseArgs(%v) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && opts != tt.want {
+			t.Errorf("releaseArgs(%v) = %+v, want %+v", tt.args, opts, tt.want)
+		}
+	}
+}
+
+func TestReleaseScreen(t *testing.T) {
+	m := initialDemoModel()
+	m.wsManager = workspace.NewManager(t.TempDir())
+	m, cmd := m.handleKeySummary(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("V")})
+	if m.state != stateRelease || cmd == nil {
+		t.Fatalf("Expected the release screen, got state %v", m.state)
+	}
+	m.handleReleasePlanMsg(cmd().(releasePlanMsg))
+	if m.releaseErr != nil || m.releasePlan == nil || !strings.Contains(m.View(), m.releasePlan.Tag) {
+		t.Fatalf("Expected a plan on the release screen, got %+v, %v", m.releasePlan, m.releaseErr)
+	}
+
+	// the next channel is alpha
+	m, cmd = m.handleKeyRelease(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("c")})
+	m.handleReleasePlanMsg(cmd().(releasePlanMsg))
+	tag := m.releasePlan.Tag
+	if m.releaseChannel != "alpha" || !strings.HasSuffix(tag, "-alpha.1") {
+		t.Fatalf("Expected an alpha, got %q on %q", tag, m.releaseChannel)
+	}
+
+	// the notes written for the upcoming release go with it
+	m.wsManager.SetUpcomingNotes(workspace.ReleaseNotes{Intro: "Alpha time.", Highlights: []string{"Demo mode"}})
+	_, cmd = m.handleKeyRelease(tea.KeyMsg{Type: tea.KeyEnter})
+	if _, ok := cmd().(successMsg); !ok {
+		t.Fatal("Expected the release to be tagged")
+	}
+	if tags, _ := m.git.GetTags(context.Background()); len(tags) == 0 || tags[0] != tag {
+		t.Errorf("Expected %s to be tagged, got %v", tag, tags)
+	}
+	meta, _ := m.wsManager.Load()
+	if len(meta.Releases) != 1 || meta.Releases[0].Tag != tag {
+		t.Fatalf("Expected %s to be recorded, got %+v", tag, meta.Releases)
+	}
+	if rec := meta.Releases[0]; rec.Intro != "Alpha time." || !reflect.DeepEqual(rec.Highlights, []string{"Demo mode"}) || meta.Upcoming != nil {
+		t.Errorf("Expected the upcoming notes moved into %s, got %q %v, upcoming %+v", tag, rec.Intro, rec.Highlights, meta.Upcoming)
+	}
+}
+
+func TestChangelogTextEditor(t *testing.T) {
+	m := initialDemoModel()
+	m.state = stateHistory
+	m, _ = m.handleKeyHistory(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e")})
+	if m.state != stateChangelogText {
+		t.Fatalf("Expected the changelog text editor, got state %v", m.state)
+	}
+
+	// enter starts a new line of the description, ctrl+s saves
+	m, _ = m.handleKeyChangelogText(tea.KeyMsg{Type: tea.KeyTab})
+	for _, key := range []tea.KeyMsg{
+		{Type: tea.KeyRunes, Runes: []rune("first")},
+		{Type: tea.KeyEnter},
+		{Type: tea.KeyRunes, Runes: []rune("second")},
+	} {
+		m, _ = m.handleKeyChangelogText(key)
+	}
+	if m.state != stateChangelogText || m.entryDesc.Value() != "first\nsecond" {
+		t.Fatalf("Expected a two-line description, got %q in state %v", m.entryDesc.Value(), m.state)
+	}
+	m, cmd := m.handleKeyChangelogText(tea.KeyMsg{Type: tea.KeyCtrlS})
+	if m.state != stateHistory || cmd == nil {
+		t.Errorf("Expected ctrl+s to save, got state %v", m.state)
+	}
+}
+
+func TestHistoryMetaChanged(t *testing.T) {
+	meta := &workspace.Meta{Hidden: map[string]bool{"a1": true}, Subjects: map[string]string{"a1": "Faster"}}
+	tests := []struct {
+		name   string
+		after  *workspace.Meta
+		change bool
+	}{
+		{"same overrides", &workspace.Meta{Hidden: map[string]bool{"a1": true}, Subjects: map[string]string{"a1": "Faster"}, ActiveWorkspace: "ws"}, false},
+		{"hidden", &workspace.Meta{Subjects: map[string]string{"a1": "Faster"}}, true},
+		{"subject", &workspace.Meta{Hidden: map[string]bool{"a1": true}, Subjects: map[string]string{"a1": "Quicker"}}, true},
+		{"description", &workspace.Meta{Hidden: map[string]bool{"a1": true}, Subjects: map[string]string{"a1": "Faster"}, Descriptions: map[string]string{"a1": "Why"}}, true},
+		{"first load", nil, true},
+	}
+	for _, tt := range tests {
+		before, after := meta, tt.after
+		if after == nil {
+			before, after = nil, meta
+		}
+		if got := historyMetaChanged(before, after); got != tt.change {
+			t.Errorf("%s: historyMetaChanged = %v, want %v", tt.name, got, tt.change)
+		}
+	}
+}
+
+func TestReleaseScreen_NoConfig(t *testing.T) {
+	m := initialDemoModel()
+	m.cfg = nil
+	m, cmd := m.handleKeySummary(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("V")})
+	if m.state != stateRelease || cmd == nil || m.releaseChannel != "" {
+		t.Fatalf("Expected the release screen on the final channel, got state %v on %q", m.state, m.releaseChannel)
+	}
+	m.handleReleasePlanMsg(cmd().(releasePlanMsg))
+	if m, _ = m.handleKeyRelease(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("c")}); m.releaseChannel != "alpha" {
+		t.Errorf("Expected the next channel to be alpha, got %q", m.releaseChannel)
+	}
+}
+
+func TestTagRelease_VersionFiles(t *testing.T) {
+	t.Chdir(t.TempDir())
+	os.WriteFile("constants.go", []byte("package main\n\nvar version = \"1.3.0\"\n"), 0644)
+	os.WriteFile("package.json", []byte("{\n  \"version\": \"1.3.0\"\n}\n"), 0644)
+	ctx := context.Background()
+	mock := git.NewMockRunner()
+	mock.Files = []git.FileStatus{{Path: "notes.txt", Staged: true}}
+	version, _ := changelog.ParseSemVer("1.4.0")
+	plan := &changelog.VersionPlan{Version: version, Tag: "v1.4.0", VersionFiles: []config.VersionFile{
+		{Path: "constants.go", Pattern: `var version = "(.*)"`},
+		{Path: "package.json", Key: "version"},
+	}}
+
+	if err := tagRelease(ctx, mock, plan); err == nil || !strings.Contains(err.Error(), "notes.txt") {
+		t.Fatalf("Expected other staged changes to stop the release, got %v", err)
+	}
+	mock.Files = []git.FileStatus{{Path: "./package.json", Modified: true}}
+	if err := tagRelease(ctx, mock, plan); err == nil || !strings.Contains(err.Error(), "package.json") {
+		t.Fatalf("Expected changes to a version file to stop the release, got %v", err)
+	}
+	mock.Files = nil
+	if err := tagRelease(ctx, mock, plan); err != nil {
+		t.Fatalf("tagRelease failed: %v", err)
+	}
+	if data, _ := os.ReadFile("constants.go"); !strings.Contains(string(data), `var version = "1.4.0"`) {
+		t.Errorf("Expected constants.go at 1.4.0, got %s", data)
+	}
+	if data, _ := os.ReadFile("package.json"); !strings.Contains(string(data), `"version": "1.4.0"`) {
+		t.Errorf("Expected package.json at 1.4.0, got %s", data)
+	}
+	if mock.Commits[0].Message != "chore(release): v1.4.0" || mock.TagCommits["v1.4.0"] != mock.Commits[0].Hash {
+		t.Errorf("Expected v1.4.0 on the release commit, got %q tagged %s", mock.Commits[0].Message, mock.TagCommits["v1.4.0"])
+	}
+
+	// the files already carry the version, nothing to commit
+	commits := len(mock.Commits)
+	plan.Tag = "v1.4.0-again"
+	if err := tagRelease(ctx, mock, plan); err != nil || len(mock.Commits) != commits {
+		t.Errorf("Expected no release commit for unchanged files, got %v", err)
+	}
+
+	// a failing tag undoes the release commit and restores the files
+	var ran []string
+	mock.RunFunc = func(ctx context.Context, args ...string) (string, error) {
+		ran = append(ran, strings.Join(args, " "))
+		return "", nil
+	}
+	head := mock.Commits[0].Hash
+	plan.Version, _ = changelog.ParseSemVer("1.5.0")
+	plan.Tag = "v1.4.0"
+	if err := tagRelease(ctx, mock, plan); err == nil {
+		t.Fatal("Expected the existing tag to fail the release")
+	}
+	if !reflect.DeepEqual(ran, []string{"reset --soft " + head}) {
+		t.Errorf("Expected the release commit to be undone, ran %v", ran)
+	}
+	if data, _ := os.ReadFile("constants.go"); !strings.Contains(string(data), `var version = "1.4.0"`) {
+		t.Errorf("Expected constants.go restored to 1.4.0, got %s", data)
+	}
+}
+
+func TestSummaryPackages(t *testing.T) {
+	m := initialDemoModel()
+	if _, cmd := m.handleKeySummary(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("P")}); cmd != nil {
+		t.Fatal("Expected [P] to do nothing without packages")
+	}
+
+	m.cfg = &config.Config{Packages: []config.Package{{Name: "api", Path: "api"}, {Name: "web", Path: "web"}}}
+	var picked []string
+	for i := 0; i < 3; i++ {
+		m, _ = m.handleKeySummary(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("P")})
+		m.isUpdating = false
+		picked = append(picked, m.summaryPackage)
+	}
+	if !reflect.DeepEqual(picked, []string{"api", "web", ""}) {
+		t.Fatalf("Expected [P] to cycle through the packages, got %v", picked)
+	}
+	m.summaryPackage = "web"
+	if gen := m.newGenerator(); gen.Package == nil || gen.ChangelogFile() != "web/CHANGELOG.md" {
+		t.Errorf("Expected the generator of the web package, got %+v", gen.Package)
+	}
+	if !strings.Contains(m.viewSummary(), "package: web") {
+		t.Error("Expected the package in the summary status")
+	}
+}
+
+func TestReleaseScreen_ConfirmMajor(t *testing.T) {
+	m := initialDemoModel()
+	m.wsManager = workspace.NewManager(t.TempDir())
+	m.state = stateRelease
+	m.releasePlan = &changelog.VersionPlan{Tag: "v2.0.0", Impact: "major", Major: true, Confirm: true}
+	key := func(s string) tea.KeyMsg {
+		if s == "enter" {
+			return tea.KeyMsg{Type: tea.KeyEnter}
+		}
+		return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+	}
+
+	m, cmd := m.handleKeyRelease(key("enter"))
+	if cmd != nil || !m.releaseConfirm || !strings.Contains(m.View(), "[y] to confirm") {
+		t.Fatal("Expected enter to ask for confirmation first")
+	}
+	m, cmd = m.handleKeyRelease(key("x"))
+	if cmd != nil || m.releaseConfirm {
+		t.Fatal("Expected any other key to cancel")
+	}
+	m, _ = m.handleKeyRelease(key("enter"))
+	_, cmd = m.handleKeyRelease(key("y"))
+	if cmd == nil {
+		t.Fatal("Expected [y] to tag the release")
+	}
+	if _, ok := cmd().(successMsg); !ok {
+		t.Error("Expected the major release to be tagged")
+	}
+}
+
+func TestNextValue(t *testing.T) {
+	values := []string{"feature", "fix"}
+	got := []string{}
+	current := ""
+	for i := 0; i < 4; i++ {
+		current = nextValue(values, current)
+		got = append(got, current)
+	}
+	if strings.Join(got, ",") != "feature,fix,,feature" {
+		t.Errorf("nextValue cycle = %v", got)
+	}
+	if nextValue(nil, "") != "" || nextValue(values, "gone") != "" {
+		t.Error("nextValue should reset to no filter")
+	}
+}
+
+func TestSummaryView_FilterSearchCollapse(t *testing.T) {
+	m := initialDemoModel()
+	m.summaryViewport.Height, m.summaryViewport.Width = 100, 200
+	rels, err := m.generateSummary(context.Background(), m.newGenerator())
+	if err != nil {
+		t.Fatalf("generateSummary failed: %v", err)
+	}
+	m.handleSummaryMsg(summaryMsg{releases: rels})
+	if !strings.Contains(m.summaryViewport.View(), "add demo mode toggle") {
+		t.Fatalf("Expected the demo changes in the summary:\n%s", m.summaryViewport.View())
+	}
+
+	key := func(s string) tea.KeyMsg {
+		if s == "enter" {
+			return tea.KeyMsg{Type: tea.KeyEnter}
+		}
+		return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+	}
+
+	// filter by tag: the first tag is "feature"
+	m, _ = m.handleKeySummary(key("t"))
+	view := m.summaryViewport.View()
+	if m.summaryFilter.Tag != "feature" || strings.Contains(view, "memory leak") || !strings.Contains(view, "demo mode toggle") {
+		t.Errorf("Expected only features with filter %+v:\n%s", m.summaryFilter, view)
+	}
+
+	// search as you type, within the tag filter
+	m, _ = m.handleKeySummary(key("/"))
+	for _, r := range "grouping" {
+		m, _ = m.handleKeySummary(key(string(r)))
+	}
+	m, _ = m.handleKeySummary(key("enter"))
+	view = m.summaryViewport.View()
+	if m.searching || m.summaryFilter.Query != "grouping" || strings.Contains(view, "demo mode toggle") || !strings.Contains(view, "workspace grouping") {
+		t.Errorf("Expected the search to narrow the view, filter %+v:\n%s", m.summaryFilter, view)
+	}
+
+	// collapse the release under the cursor
+	m, _ = m.handleKeySummary(key("enter"))
+	view = m.summaryViewport.View()
+	if strings.Contains(view, "workspace grouping") || !strings.Contains(view, "▸ Release") {
+		t.Errorf("Expected a collapsed release:\n%s", view)
+	}
+
+	m, _ = m.handleKeySummary(key("x"))
+	if !m.summaryFilter.IsEmpty() {
+		t.Errorf("Expected the filters to be cleared, got %+v", m.summaryFilter)
+	}
+}
+
+func TestReleaseNotesEditor(t *testing.T) {
+	text := "Startup is twice as fast.\n\nThanks to everyone who tested the betas.\n\n- Parallel fetch\n* HTML export\n-  \n"
+	notes := parseReleaseNotes(text)
+	want := workspace.ReleaseNotes{
+		Intro:      "Startup is twice as fast.\n\nThanks to everyone who tested the betas.",
+		Highlights: []string{"Parallel fetch", "HTML export"},
+	}
+	if !reflect.DeepEqual(notes, want) {
+		t.Fatalf("parseReleaseNotes = %+v, want %+v", notes, want)
+	}
+	if got := parseReleaseNotes(releaseNotesText(notes)); !reflect.DeepEqual(got, notes) {
+		t.Errorf("Notes don't round-trip: %+v", got)
+	}
+
+	// written in the summary view, saved to meta.json and shown on the upcoming release
+	m := initialDemoModel()
+	m.wsManager = workspace.NewManager(t.TempDir())
+	m.summaryViewport.Height, m.summaryViewport.Width = 100, 200
+	m, _ = m.handleKeySummary(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("N")})
+	if m.state != stateReleaseNotes {
+		t.Fatalf("Expected the notes editor, got state %v", m.state)
+	}
+	m.releaseNotes.SetValue("A big one.\n- Demo mode")
+	m, cmd := m.handleKeyReleaseNotes(tea.KeyMsg{Type: tea.KeyCtrlS})
+	if m.state != stateSummary || cmd == nil {
+		t.Fatalf("Expected to save and go back to the summary, got state %v", m.state)
+	}
+	for _, msg := range cmd().(tea.BatchMsg) {
+		if sm, ok := msg().(summaryMsg); ok {
+			m.handleSummaryMsg(sm)
+		}
+	}
+	if view := m.summaryViewport.View(); !strings.Contains(view, "A big one.") || !strings.Contains(view, "* Demo mode") {
+		t.Errorf("Expected the notes in the summary:\n%s", view)
+	}
+	if meta, _ := m.wsManager.Load(); meta.Upcoming == nil || meta.Upcoming.Intro != "A big one." {
+		t.Errorf("Expected the notes in meta.json, got %+v", meta.Upcoming)
+	}
+}
+
+func TestHeader_ConfigError(t *testing.T) {
+	m := initialDemoModel()
+	m.cfgErr = errors.New("invalid config: exclude.messages: missing closing )")
+	if view := m.View(); !strings.Contains(view, "exclude.messages: missing closing )") || !strings.Contains(view, "using the default settings") {
+		t.Errorf("Expected the config error in the header:\n%s", view)
+	}
+}
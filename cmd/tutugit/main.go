@@ -45,6 +45,12 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case summaryMsg:
 		m.handleSummaryMsg(msg)
 		return m, nil
+	case summaryRefsMsg:
+		m.handleSummaryRefsMsg(msg)
+		return m, nil
+	case releasePlanMsg:
+		m.handleReleasePlanMsg(msg)
+		return m, nil
 	case diffMsg:
 		m.handleDiffMsg(msg)
 		return m, nil
@@ -58,12 +64,20 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		switch m.state {
 		case stateSummary:
 			return m.handleKeySummary(msg)
+		case stateSummaryRange:
+			return m.handleKeySummaryRange(msg)
+		case stateReleaseNotes:
+			return m.handleKeyReleaseNotes(msg)
+		case stateRelease:
+			return m.handleKeyRelease(msg)
 		case stateReflog:
 			return m.handleKeyReflog(msg)
 		case stateReflogConfirm:
 			return m.handleKeyReflogConfirm(msg)
 		case stateHistory:
 			return m.handleKeyHistory(msg)
+		case stateChangelogText:
+			return m.handleKeyChangelogText(msg)
 		case stateRebasePrepare:
 			return m.handleKeyRebasePrepare(msg)
 		case stateRebaseOngoing:
@@ -113,6 +127,18 @@ func main() {
 			fmt.Println("🚀 tutugit initialized successfully!")
 			fmt.Println(".tutugit directory created with meta.json and config.yml")
 			return
+		case "export":
+			if err := runExport(os.Args[2:]); err != nil {
+				fmt.Printf("Error exporting: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "release":
+			if err := runRelease(os.Args[2:]); err != nil {
+				fmt.Printf("Error releasing: %v\n", err)
+				os.Exit(1)
+			}
+			return
 		case "demo":
 			m := initialDemoModel()
 			p := tea.NewProgram(m)
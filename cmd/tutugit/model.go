@@ -4,12 +4,14 @@ import (
 	"fmt"
 	"os"
 
+	"tutugit/internal/changelog"
 	"tutugit/internal/config"
 	"tutugit/internal/diff"
 	"tutugit/internal/git"
 	"tutugit/internal/hygiene"
 	"tutugit/internal/workspace"
 
+	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
@@ -21,6 +23,7 @@ type model struct {
 	wsManager       *workspace.Manager
 	cfgManager      *config.Manager
 	cfg             *config.Config
+	cfgErr          error // why config.yml couldn't be loaded, the defaults are used instead
 	hygiene         *hygiene.Analyzer
 	meta            *workspace.Meta
 	report          *hygiene.HealthReport
@@ -46,15 +49,39 @@ type model struct {
 	err             error
 	state           state
 	commitMsg       textinput.Model
+	migrationNotes  textinput.Model
 	newWsName       textinput.Model
 	newWsDesc       textinput.Model
+	entrySubject    textinput.Model // changelog text overrides, edited from the history view
+	entryDesc       textarea.Model  // may span several paragraphs
+	entryHash       string
 	selectedTag     int
 	isUpdating      bool
 	isRebasing      bool
 	width           int
 	height          int
-	summaryContent  string
 	summaryViewport viewport.Model
+	summaryLayout   string               // layout picked in the summary view, overriding the config
+	summaryPackage  string               // monorepo package of the summary and the release screen, "" for the whole repository
+	exportFormat    string               // format written by E in the summary view, markdown when empty
+	summaryReleases []*changelog.Release // releases of the summary, before filtering
+	summaryTemplate changelog.Template   // custom summary layout, nil for the built-in one
+	summaryFilter   changelog.Filter
+	summaryCursor   int             // release under the cursor, among the filtered ones
+	summaryLines    []int           // first line of each filtered release in the viewport
+	collapsed       map[string]bool // releases collapsed in the summary, by version
+	summarySearch   textinput.Model
+	searching       bool
+	summaryBase     summaryRef // range picked in the summary view, the whole history when unset
+	summaryHead     summaryRef
+	summaryRefs     []summaryRef // choices of the range picker
+	refCursor       int
+	pickingHead     bool           // the range picker asks for the head, once the base is picked
+	releaseNotes    textarea.Model // intro and highlights of the next release, edited from the summary view
+	releaseChannel  string         // pre-release channel of the release screen, "" for a final release
+	releasePlan     *changelog.VersionPlan
+	releaseErr      error  // why there is no plan, shown on the release screen
+	releaseConfirm  bool   // enter pressed on a guarded major release, waiting for [y]
 	manualImpact    string // empty if auto
 	decidedImpact   string // the actual impact being used
 	suggestedImpact string
@@ -72,7 +99,22 @@ type reflogMsg []git.ReflogEntry
 type worktreesMsg []git.Worktree
 type rebaseStatusMsg bool
 type rebaseStepsMsg []git.RebaseStep
-type summaryMsg string
+type summaryMsg struct {
+	releases []*changelog.Release
+	tmpl     changelog.Template // custom summary layout, nil for the built-in one
+}
+type summaryRefsMsg []summaryRef
+type releasePlanMsg struct {
+	plan *changelog.VersionPlan
+	err  error
+}
+
+// summaryRef is a revision offered by the range picker: a tag, a branch or a commit
+type summaryRef struct {
+	rev   string // "" for the start of history
+	name  string // short name, used as the version of the range
+	label string
+}
 type errMsg error
 type successMsg string
 
@@ -86,9 +128,9 @@ func initialModel() (model, error) {
 	w := workspace.NewManager(cwd)
 	c := config.NewManager(cwd)
 
-	// Load config (defaults if not present)
-	cfg, err := c.Load()
-	if err != nil {
+	// Load config (defaults if not present, or if it can't be read: the header shows why)
+	cfg, cfgErr := c.Load()
+	if cfgErr != nil {
 		cfg = config.DefaultConfig()
 	}
 
@@ -96,12 +138,24 @@ func initialModel() (model, error) {
 	ti.Placeholder = "Commit message..."
 	ti.Focus()
 
+	mn := textinput.New()
+	mn.Placeholder = "What breaks and how to migrate..."
+
 	wn := textinput.New()
 	wn.Placeholder = "Workspace name..."
 
 	wd := textinput.New()
 	wd.Placeholder = "Description (optional)..."
 
+	es := textinput.New()
+	es.Placeholder = "Subject shown in the changelog..."
+
+	ss := textinput.New()
+	ss.Placeholder = "Search subjects, authors, hashes..."
+	ss.Prompt = "/"
+
+	rn := newReleaseNotesArea()
+
 	vp := newStyledViewport("62")
 	hp := newStyledViewport("63")
 	rp := newStyledViewport("64")
@@ -112,16 +166,23 @@ func initialModel() (model, error) {
 		wsManager:       w,
 		cfgManager:      c,
 		cfg:             cfg,
+		cfgErr:          cfgErr,
 		hygiene:         hygiene.NewAnalyzer(g, w),
 		state:           stateMain,
 		commitMsg:       ti,
+		migrationNotes:  mn,
 		newWsName:       wn,
 		newWsDesc:       wd,
+		entrySubject:    es,
+		entryDesc:       newEntryDescArea(),
 		expandedFile:    noFileSelected,
 		diffViewport:    vp,
 		historyViewport: hp,
 		reflogViewport:  rp,
 		summaryViewport: sp,
+		summarySearch:   ss,
+		releaseNotes:    rn,
+		collapsed:       make(map[string]bool),
 		expandedHistory: make(map[string]bool),
 		isUpdating:      true,
 		decidedImpact:   "patch",
@@ -157,6 +218,8 @@ func (m model) View() string {
 		return m.viewDiff()
 	case stateHistory:
 		return m.viewHistory()
+	case stateChangelogText:
+		return m.viewChangelogText()
 	case stateReflog:
 		return m.viewReflog()
 	case stateReflogConfirm:
@@ -169,6 +232,12 @@ func (m model) View() string {
 		return m.viewRebaseOngoing()
 	case stateSummary:
 		return m.viewSummary()
+	case stateSummaryRange:
+		return m.viewSummaryRange()
+	case stateReleaseNotes:
+		return m.viewReleaseNotes()
+	case stateRelease:
+		return m.viewRelease()
 	}
 
 	return m.viewMain()
@@ -0,0 +1,302 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"tutugit/internal/changelog"
+	"tutugit/internal/config"
+	"tutugit/internal/git"
+	"tutugit/internal/workspace"
+)
+
+// releaseUsage is printed when "tutugit release" gets arguments it doesn't understand
+const releaseUsage = "usage: tutugit release [--package name] [--channel name | --final] [--confirm-major] [--dry-run]"
+
+// releaseOptions are the arguments of "tutugit release"
+type releaseOptions struct {
+	pkg        string // monorepo package to release, "" for the whole repository
+	channel    string
+	channelSet bool // --channel or --final given, overriding the config
+	dryRun     bool
+	confirm    bool // --confirm-major, for major releases guarded by release.confirm_major
+}
+
+// releaseArgs parses "[--package name] [--channel name | --final] [--confirm-major] [--dry-run]"
+func releaseArgs(args []string) (releaseOptions, error) {
+	var opts releaseOptions
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; arg {
+		case "-p", "--package":
+			if i+1 >= len(args) {
+				return opts, fmt.Errorf("%s needs a package name\n%s", arg, releaseUsage)
+			}
+			i++
+			opts.pkg = args[i]
+		case "-c", "--channel":
+			if i+1 >= len(args) {
+				return opts, fmt.Errorf("%s needs a channel name\n%s", arg, releaseUsage)
+			}
+			i++
+			opts.channel, opts.channelSet = args[i], true
+		case "--final":
+			opts.channel, opts.channelSet = "", true
+		case "--confirm-major":
+			opts.confirm = true
+		case "-n", "--dry-run":
+			opts.dryRun = true
+		default:
+			return opts, fmt.Errorf("unexpected argument %q\n%s", arg, releaseUsage)
+		}
+	}
+	if err := changelog.ValidateChannel(opts.channel); err != nil {
+		return opts, err
+	}
+	return opts, nil
+}
+
+// describePlan tells what a release would do, e.g. "v1.3.0 → v1.4.0-beta.2 (minor, beta channel)"
+func describePlan(plan *changelog.VersionPlan) string {
+	previous := plan.Previous
+	if previous == "" {
+		previous = "first release"
+	}
+	channel := "final release"
+	if plan.Channel != "" {
+		channel = plan.Channel + " channel"
+	}
+	if plan.Policy != "" && plan.Policy != changelog.PolicySemVer {
+		channel += ", " + plan.Policy
+	}
+	s := fmt.Sprintf("%s → %s (%s, %s)", previous, plan.Tag, plan.Impact, channel)
+	if plan.Channel == "" && len(plan.Prereleases) > 0 {
+		s += "\nPromotes " + strings.Join(plan.Prereleases, ", ")
+	}
+	if len(plan.VersionFiles) > 0 {
+		paths := make([]string, len(plan.VersionFiles))
+		for i, vf := range plan.VersionFiles {
+			paths[i] = vf.Path
+		}
+		s += "\nSets the version in " + strings.Join(paths, ", ")
+	}
+	if plan.Confirm {
+		s += "\nMajor release: needs confirmation"
+	}
+	return s
+}
+
+// tagRelease creates the tag of a planned release, after committing its version files.
+// When tagging fails, the release commit is undone and the files get their old content back.
+func tagRelease(ctx context.Context, g git.GitProvider, plan *changelog.VersionPlan) error {
+	undo, err := commitVersionFiles(ctx, g, plan)
+	if err != nil {
+		return err
+	}
+	if err := g.CreateTag(ctx, plan.Tag, "Release "+plan.Tag); err != nil {
+		if undoErr := undo(); undoErr != nil {
+			return fmt.Errorf("%w (undoing the release commit failed: %v)", err, undoErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// recordVersion freezes a tagged release in meta.json, so it keeps its notes whatever happens
+// to the tags and metadata afterwards. A release that was just tagged takes the notes written
+// for the upcoming release, which are cleared for the next one. It reports whether the release
+// was recorded already.
+func recordVersion(ctx context.Context, gen *changelog.Generator, ws *workspace.Manager, version string, justTagged bool) (bool, error) {
+	rels, err := gen.GenerateFull(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, rel := range rels {
+		if rel.Version != version {
+			continue
+		}
+		if rel.Recorded {
+			return true, nil
+		}
+		// the upcoming notes belong to the whole repository, not to a package
+		moveUpcoming := rel.Intro != "" || len(rel.Highlights) > 0
+		if justTagged && gen.Package == nil {
+			meta, err := ws.Load()
+			if err != nil {
+				return false, err
+			}
+			if meta.Upcoming != nil && !meta.Upcoming.IsEmpty() {
+				withNotes := *rel
+				withNotes.Intro, withNotes.Highlights = meta.Upcoming.Intro, meta.Upcoming.Highlights
+				rel, moveUpcoming = &withNotes, true
+			}
+		}
+		rec, err := gen.Record(rel)
+		if err != nil {
+			return false, err
+		}
+		if err := ws.RecordRelease(rec); err != nil {
+			return false, err
+		}
+		// the upcoming notes now belong to the recorded release
+		if moveUpcoming {
+			if err := ws.SetUpcomingNotes(workspace.ReleaseNotes{}); err != nil {
+				return false, err
+			}
+		}
+		return false, nil
+	}
+	return false, fmt.Errorf("release %s not found", version)
+}
+
+// commitVersionFiles rewrites the version files to the planned version and commits them as
+// "chore(release): <tag>". Every file is checked before any is written, the files must have
+// no changes of their own, and nothing is committed when they already carry the version.
+// On failure the files are restored; on success the returned func undoes the commit.
+func commitVersionFiles(ctx context.Context, g git.GitProvider, plan *changelog.VersionPlan) (func() error, error) {
+	nothing := func() error { return nil }
+	if len(plan.VersionFiles) == 0 {
+		return nothing, nil
+	}
+	// the release commit must hold the version files alone, and nothing but the version
+	versionPaths := make(map[string]bool, len(plan.VersionFiles))
+	for _, vf := range plan.VersionFiles {
+		versionPaths[filepath.ToSlash(filepath.Clean(vf.Path))] = true
+	}
+	status, err := g.ParseStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range status {
+		if versionPaths[filepath.ToSlash(filepath.Clean(f.Path))] {
+			return nil, fmt.Errorf("%s has uncommitted changes: commit or stash them before releasing", f.Path)
+		}
+		if f.Staged {
+			return nil, fmt.Errorf("%s is staged: commit or unstage it before releasing", f.Path)
+		}
+	}
+
+	type rewrite struct {
+		path     string
+		original []byte
+		data     []byte
+		mode     os.FileMode
+	}
+	var rewrites []rewrite
+	for _, vf := range plan.VersionFiles {
+		info, err := os.Stat(vf.Path)
+		if err != nil {
+			return nil, fmt.Errorf("version file: %w", err)
+		}
+		data, err := os.ReadFile(vf.Path)
+		if err != nil {
+			return nil, fmt.Errorf("version file: %w", err)
+		}
+		updated, err := changelog.SetVersion(data, vf, plan.Version.String())
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(data, updated) {
+			rewrites = append(rewrites, rewrite{path: vf.Path, original: data, data: updated, mode: info.Mode().Perm()})
+		}
+	}
+	if len(rewrites) == 0 {
+		return nothing, nil
+	}
+
+	// restore unstages the files and writes their old content back
+	restore := func() error {
+		var errs []error
+		for _, rw := range rewrites {
+			errs = append(errs, g.UnstageFile(ctx, rw.path), os.WriteFile(rw.path, rw.original, rw.mode))
+		}
+		return errors.Join(errs...)
+	}
+	head, err := g.GetLastCommitHash(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, rw := range rewrites {
+		err := os.WriteFile(rw.path, rw.data, rw.mode)
+		if err == nil {
+			err = g.StageFile(ctx, rw.path)
+		}
+		if err != nil {
+			return nil, errors.Join(err, restore())
+		}
+	}
+	if err := g.Commit(ctx, changelog.ReleaseCommitPrefix+plan.Tag); err != nil {
+		return nil, errors.Join(err, restore())
+	}
+
+	// a soft reset keeps any other work in progress
+	return func() error {
+		if _, err := g.Run(ctx, "reset", "--soft", head); err != nil {
+			return err
+		}
+		return restore()
+	}, nil
+}
+
+// runRelease computes the next version of the repository in the working directory and tags it
+func runRelease(args []string) error {
+	opts, err := releaseArgs(args)
+	if err != nil {
+		return err
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+	cfg, err := config.NewManager(cwd).Load()
+	if err != nil {
+		return err
+	}
+	meta, err := workspace.NewManager(cwd).Load()
+	if err != nil {
+		return err
+	}
+	if !opts.channelSet {
+		opts.channel = cfg.Release.Channel
+	}
+
+	m := model{git: git.NewRunner(cwd), cfg: cfg, meta: meta}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	gen := m.newGenerator()
+	if opts.pkg != "" {
+		if gen, err = gen.ForPackage(opts.pkg); err != nil {
+			return err
+		}
+	}
+	plan, err := gen.PlanRelease(ctx, opts.channel)
+	if errors.Is(err, changelog.ErrNothingToRelease) {
+		fmt.Println("Nothing to release: no changes since the last release.")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(describePlan(plan))
+	if opts.dryRun {
+		return nil
+	}
+	if plan.Confirm && !opts.confirm {
+		return fmt.Errorf("%s is a major release: run again with --confirm-major to tag it", plan.Tag)
+	}
+	if err := tagRelease(ctx, m.git, plan); err != nil {
+		return err
+	}
+	fmt.Printf("Tagged %s\n", plan.Tag)
+	if _, err := recordVersion(ctx, gen, workspace.NewManager(cwd), plan.Tag, true); err != nil {
+		return fmt.Errorf("%s is tagged but could not be recorded in meta.json: %w", plan.Tag, err)
+	}
+	return nil
+}
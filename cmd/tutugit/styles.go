@@ -1,6 +1,7 @@
 package main
 
 import (
+	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/viewport"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -61,3 +62,23 @@ func newStyledViewport(borderColor string) viewport.Model {
 		Padding(0, 1)
 	return vp
 }
+
+// newEntryDescArea creates the text area of the changelog description of a commit
+func newEntryDescArea() textarea.Model {
+	ta := textarea.New()
+	ta.Placeholder = "Longer description (optional)..."
+	ta.ShowLineNumbers = false
+	ta.CharLimit = 0
+	ta.SetHeight(6)
+	return ta
+}
+
+// newReleaseNotesArea creates the text area of the release notes editor
+func newReleaseNotesArea() textarea.Model {
+	ta := textarea.New()
+	ta.Placeholder = "A paragraph introducing the release, then one highlight per line:\n\n- Faster startup\n- Export to HTML"
+	ta.ShowLineNumbers = false
+	ta.CharLimit = 0
+	ta.SetHeight(12)
+	return ta
+}
@@ -2,12 +2,16 @@ package main
 
 import (
 	"context"
+	"maps"
+	"slices"
 	"strings"
 
+	"tutugit/internal/changelog"
 	"tutugit/internal/diff"
 	"tutugit/internal/git"
 	"tutugit/internal/workspace"
 
+	"github.com/charmbracelet/bubbles/textarea"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
@@ -21,7 +25,7 @@ func (m *model) handleWindowResize(msg tea.WindowSizeMsg) {
 	m.historyViewport.Width = msg.Width
 	m.reflogViewport.Height = msg.Height - viewportReservedSpace
 	m.reflogViewport.Width = msg.Width
-	m.summaryViewport.Height = msg.Height - viewportReservedSpace
+	m.summaryViewport.Height = msg.Height - viewportReservedSpace - 1 // two lines of shortcuts
 	m.summaryViewport.Width = msg.Width
 }
 
@@ -44,11 +48,26 @@ func (m *model) handleFilesMsg(msg filesMsg) {
 
 // handleMetaMsg handles workspace metadata updates
 func (m *model) handleMetaMsg(msg metaMsg) {
+	previous := m.meta
 	m.meta = msg
 	if m.state == stateNewWorkspace || m.state == stateWorkspaces {
 		m.state = stateWorkspaces
 	}
 	m.isUpdating = false
+	if historyMetaChanged(previous, msg) {
+		m.renderHistory()
+	}
+}
+
+// historyMetaChanged reports whether the metadata shown in the history view (hidden
+// commits and changelog text) differs between two loads
+func historyMetaChanged(before, after *workspace.Meta) bool {
+	if before == nil || after == nil {
+		return before != after
+	}
+	return !maps.Equal(before.Hidden, after.Hidden) ||
+		!maps.Equal(before.Subjects, after.Subjects) ||
+		!maps.Equal(before.Descriptions, after.Descriptions)
 }
 
 // handleReportMsg handles hygiene report updates
@@ -94,10 +113,20 @@ func (m *model) handleRebaseStepsMsg(msg rebaseStepsMsg) {
 
 // handleSummaryMsg handles summary content updates
 func (m *model) handleSummaryMsg(msg summaryMsg) {
-	m.summaryContent = string(msg)
+	m.summaryReleases = msg.releases
+	m.summaryTemplate = msg.tmpl
 	m.isUpdating = false
 	m.state = stateSummary
-	m.summaryViewport.SetContent(m.summaryContent)
+	m.renderSummary()
+}
+
+// handleSummaryRefsMsg opens the range picker with the revisions found
+func (m *model) handleSummaryRefsMsg(msg summaryRefsMsg) {
+	m.summaryRefs = msg
+	m.refCursor = 0
+	m.pickingHead = false
+	m.isUpdating = false
+	m.state = stateSummaryRange
 }
 
 // handleDiffMsg handles diff content updates
@@ -138,6 +167,7 @@ func (m *model) handleSuccessMsg() (model, tea.Cmd) {
 	}
 	m.state = stateMain
 	m.commitMsg.Reset()
+	m.migrationNotes.Reset()
 	return *m, tea.Batch(m.fetchBranch, m.fetchFiles, m.fetchMeta, m.fetchHygiene)
 }
 
@@ -149,14 +179,137 @@ func (m *model) handleErrorMsg(msg errMsg) {
 
 // handleKeySummary handles keyboard input in summary view state
 func (m *model) handleKeySummary(msg tea.KeyMsg) (model, tea.Cmd) {
+	if m.searching {
+		return m.handleKeySummarySearch(msg)
+	}
 	switch msg.String() {
 	case "esc", "q", "L":
 		m.state = stateMain
 		return *m, nil
+	case "up", "k":
+		if m.summaryCursor > 0 {
+			m.summaryCursor--
+			m.renderSummary()
+		}
+		return *m, nil
+	case "down", "j":
+		if m.summaryCursor < len(m.summaryLines)-1 {
+			m.summaryCursor++
+			m.renderSummary()
+		}
+		return *m, nil
+	case "enter", " ":
+		if rels := m.summaryFilter.Apply(m.summaryReleases); m.summaryCursor < len(rels) {
+			version := rels[m.summaryCursor].Version
+			m.collapsed[version] = !m.collapsed[version]
+			m.renderSummary()
+		}
+		return *m, nil
+	case "z":
+		rels := m.summaryFilter.Apply(m.summaryReleases)
+		collapse := false
+		for _, rel := range rels {
+			collapse = collapse || !m.collapsed[rel.Version]
+		}
+		for _, rel := range rels {
+			m.collapsed[rel.Version] = collapse
+		}
+		m.renderSummary()
+		return *m, nil
+	case "/":
+		m.searching = true
+		m.summarySearch.SetValue(m.summaryFilter.Query)
+		m.summarySearch.CursorEnd()
+		m.summarySearch.Focus()
+		return *m, nil
+	case "t", "w", "i", "a":
+		tags, workspaces, impacts, authors := changelog.FilterValues(m.summaryReleases)
+		switch msg.String() {
+		case "t":
+			m.summaryFilter.Tag = nextValue(tags, m.summaryFilter.Tag)
+		case "w":
+			m.summaryFilter.Workspace = nextValue(workspaces, m.summaryFilter.Workspace)
+		case "i":
+			m.summaryFilter.Impact = nextValue(impacts, m.summaryFilter.Impact)
+		case "a":
+			m.summaryFilter.Author = nextValue(authors, m.summaryFilter.Author)
+		}
+		m.summaryCursor = 0
+		m.renderSummary()
+		return *m, nil
+	case "x":
+		m.summaryFilter = changelog.Filter{}
+		m.summaryCursor = 0
+		m.renderSummary()
+		return *m, nil
+	case "r":
+		if !m.isUpdating {
+			m.isUpdating = true
+			return *m, m.fetchSummaryRefs
+		}
+	case "V":
+		if !m.isUpdating {
+			m.releaseChannel = m.configuredChannel()
+			m.releasePlan, m.releaseErr, m.releaseConfirm = nil, nil, false
+			m.state = stateRelease
+			m.isUpdating = true
+			return *m, m.fetchReleasePlan
+		}
+	case "N":
+		var notes workspace.ReleaseNotes
+		if m.meta != nil && m.meta.Upcoming != nil {
+			notes = *m.meta.Upcoming
+		}
+		m.releaseNotes.SetValue(releaseNotesText(notes))
+		if m.width > 4 {
+			m.releaseNotes.SetWidth(m.width - 4)
+		}
+		m.releaseNotes.Focus()
+		m.state = stateReleaseNotes
+		return *m, textarea.Blink
+	case "R":
+		// ranges change what a release holds, only whole releases are recorded
+		rels := m.summaryFilter.Apply(m.summaryReleases)
+		if !m.isUpdating && !m.hasSummaryRange() && m.summaryCursor < len(rels) {
+			m.isUpdating = true
+			return *m, m.recordRelease(rels[m.summaryCursor].Version)
+		}
 	case "E":
 		if !m.isUpdating {
 			m.isUpdating = true
-			return *m, m.exportMarkdown()
+			if m.currentExportFormat() == changelog.FormatMarkdown {
+				return *m, m.exportMarkdown()
+			}
+			return *m, m.exportSummary(m.currentExportFormat())
+		}
+	case "F":
+		formats := changelog.ExportFormats
+		for i, f := range formats {
+			if f == m.currentExportFormat() {
+				m.exportFormat = formats[(i+1)%len(formats)]
+				break
+			}
+		}
+	case "W":
+		if !m.isUpdating {
+			m.summaryLayout = changelog.LayoutWorkspaces
+			if m.currentLayout() == changelog.LayoutWorkspaces {
+				m.summaryLayout = changelog.LayoutTags
+			}
+			m.isUpdating = true
+			return *m, m.fetchSummary()
+		}
+	case "P":
+		if names := changelog.PackageNames(m.cfg); !m.isUpdating && len(names) > 0 {
+			m.summaryPackage = nextValue(names, m.summaryPackage)
+			m.summaryCursor = 0
+			m.isUpdating = true
+			return *m, m.fetchSummary()
+		}
+	case "C":
+		if !m.isUpdating {
+			m.isUpdating = true
+			return *m, m.writeChangelog()
 		}
 	}
 	var cmd tea.Cmd
@@ -164,6 +317,211 @@ func (m *model) handleKeySummary(msg tea.KeyMsg) (model, tea.Cmd) {
 	return *m, cmd
 }
 
+// handleKeySummarySearch handles keyboard input while typing a search in the summary view.
+// The view is filtered as you type; esc clears the search.
+func (m *model) handleKeySummarySearch(msg tea.KeyMsg) (model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.summarySearch.Reset()
+		m.summaryFilter.Query = ""
+		m.searching = false
+		m.summarySearch.Blur()
+		m.summaryCursor = 0
+		m.renderSummary()
+		return *m, nil
+	case "enter":
+		m.searching = false
+		m.summarySearch.Blur()
+		return *m, nil
+	}
+	var cmd tea.Cmd
+	m.summarySearch, cmd = m.summarySearch.Update(msg)
+	if m.summarySearch.Value() != m.summaryFilter.Query {
+		m.summaryFilter.Query = m.summarySearch.Value()
+		m.summaryCursor = 0
+		m.renderSummary()
+	}
+	return *m, cmd
+}
+
+// handleKeySummaryRange handles keyboard input in the range picker of the summary view.
+// The first entry picks the whole history (as base) or HEAD (as head).
+func (m *model) handleKeySummaryRange(msg tea.KeyMsg) (model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		m.state = stateSummary
+		return *m, nil
+	case "up", "k":
+		if m.refCursor > 0 {
+			m.refCursor--
+		}
+	case "down", "j":
+		if m.refCursor < len(m.summaryRefs) {
+			m.refCursor++
+		}
+	case "enter":
+		var picked summaryRef
+		if m.refCursor > 0 {
+			picked = m.summaryRefs[m.refCursor-1]
+		}
+		if !m.pickingHead {
+			if picked.rev == "" {
+				// back to the whole history
+				m.summaryBase, m.summaryHead = summaryRef{}, summaryRef{}
+				return m.refreshSummary()
+			}
+			m.summaryBase = picked
+			m.pickingHead = true
+			m.refCursor = 0
+			return *m, nil
+		}
+		m.summaryHead = picked
+		return m.refreshSummary()
+	}
+	return *m, nil
+}
+
+// refreshSummary regenerates the summary after its range changed
+func (m *model) refreshSummary() (model, tea.Cmd) {
+	m.state = stateSummary
+	m.summaryCursor = 0
+	m.isUpdating = true
+	return *m, m.fetchSummary()
+}
+
+// handleReleasePlanMsg shows the computed next version on the release screen
+func (m *model) handleReleasePlanMsg(msg releasePlanMsg) {
+	m.releasePlan, m.releaseErr = msg.plan, msg.err
+	m.releaseConfirm = false
+	m.isUpdating = false
+}
+
+// configuredChannel returns the release channel of the config, "" when there is none
+func (m *model) configuredChannel() string {
+	if m.cfg == nil {
+		return ""
+	}
+	return m.cfg.Release.Channel
+}
+
+// handleKeyRelease handles keyboard input on the release screen
+func (m *model) handleKeyRelease(msg tea.KeyMsg) (model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		m.state = stateSummary
+		return *m, nil
+	case "c":
+		if !m.isUpdating {
+			channels := append([]string{""}, changelog.Channels...)
+			if custom := m.configuredChannel(); custom != "" && !slices.Contains(channels, custom) {
+				channels = append(channels, custom)
+			}
+			m.releaseChannel = channels[(slices.Index(channels, m.releaseChannel)+1)%len(channels)]
+			m.isUpdating = true
+			return *m, m.fetchReleasePlan
+		}
+	case "enter":
+		if !m.isUpdating && m.releasePlan != nil {
+			if m.releasePlan.Confirm {
+				m.releaseConfirm = true
+				return *m, nil
+			}
+			m.isUpdating = true
+			return *m, m.createRelease(m.releasePlan)
+		}
+	case "y":
+		if !m.isUpdating && m.releasePlan != nil && m.releaseConfirm {
+			m.releaseConfirm = false
+			m.isUpdating = true
+			return *m, m.createRelease(m.releasePlan)
+		}
+	}
+	m.releaseConfirm = false
+	return *m, nil
+}
+
+// handleKeyReleaseNotes handles keyboard input while editing the notes of the next release
+func (m *model) handleKeyReleaseNotes(msg tea.KeyMsg) (model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.releaseNotes.Blur()
+		m.state = stateSummary
+		return *m, nil
+	case "ctrl+s":
+		notes := parseReleaseNotes(m.releaseNotes.Value())
+		m.releaseNotes.Blur()
+		m.state = stateSummary
+		if m.meta != nil {
+			m.meta.Upcoming = nil
+			if !notes.IsEmpty() {
+				m.meta.Upcoming = &notes
+			}
+		}
+		m.isUpdating = true
+		return *m, tea.Batch(m.setUpcomingNotes(notes), m.fetchSummary())
+	}
+	var cmd tea.Cmd
+	m.releaseNotes, cmd = m.releaseNotes.Update(msg)
+	return *m, cmd
+}
+
+// releaseNotesText lays out release notes for the editor: the intro, then a "- " line per highlight
+func releaseNotesText(notes workspace.ReleaseNotes) string {
+	var parts []string
+	if notes.Intro != "" {
+		parts = append(parts, notes.Intro)
+	}
+	if len(notes.Highlights) > 0 {
+		parts = append(parts, "- "+strings.Join(notes.Highlights, "\n- "))
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// parseReleaseNotes reads the editor text back: lines starting with "- " or "* " are highlights,
+// the others make up the intro
+func parseReleaseNotes(text string) workspace.ReleaseNotes {
+	var notes workspace.ReleaseNotes
+	var intro []string
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "-" || trimmed == "*" {
+			continue // a highlight left empty
+		}
+		if item, ok := strings.CutPrefix(trimmed, "- "); ok {
+			notes.Highlights = appendNonEmpty(notes.Highlights, item)
+		} else if item, ok := strings.CutPrefix(trimmed, "* "); ok {
+			notes.Highlights = appendNonEmpty(notes.Highlights, item)
+		} else {
+			intro = append(intro, strings.TrimRight(line, " \t"))
+		}
+	}
+	notes.Intro = strings.TrimSpace(strings.Join(intro, "\n"))
+	return notes
+}
+
+func appendNonEmpty(list []string, item string) []string {
+	if item = strings.TrimSpace(item); item != "" {
+		list = append(list, item)
+	}
+	return list
+}
+
+// nextValue returns the value after current in values, cycling back to "" (no filter) after the last
+func nextValue(values []string, current string) string {
+	if current == "" {
+		if len(values) == 0 {
+			return ""
+		}
+		return values[0]
+	}
+	for i, v := range values {
+		if v == current && i+1 < len(values) {
+			return values[i+1]
+		}
+	}
+	return ""
+}
+
 // handleKeyReflog handles keyboard input in reflog state
 func (m *model) handleKeyReflog(msg tea.KeyMsg) (model, tea.Cmd) {
 	switch msg.String() {
@@ -238,6 +596,31 @@ func (m *model) handleKeyHistory(msg tea.KeyMsg) (model, tea.Cmd) {
 			m.renderHistory()
 		}
 		return *m, nil
+	case "x":
+		if m.historyCursor >= 0 && m.historyCursor < len(m.commits) {
+			return *m, m.toggleHidden(m.commits[m.historyCursor].Hash)
+		}
+		return *m, nil
+	case "e":
+		if m.historyCursor >= 0 && m.historyCursor < len(m.commits) {
+			c := m.commits[m.historyCursor]
+			m.entryHash = c.Hash
+			m.entrySubject.SetValue(c.Message)
+			m.entryDesc.Reset()
+			if m.meta != nil {
+				if subject := m.meta.Subjects[c.Hash]; subject != "" {
+					m.entrySubject.SetValue(subject)
+				}
+				m.entryDesc.SetValue(m.meta.Descriptions[c.Hash])
+			}
+			if m.width > 4 {
+				m.entryDesc.SetWidth(m.width - 4)
+			}
+			m.entryDesc.Blur()
+			m.entrySubject.Focus()
+			m.state = stateChangelogText
+		}
+		return *m, nil
 	case "L":
 		m.isUpdating = true
 		m.summaryViewport.SetContent("Generating summary...")
@@ -248,6 +631,37 @@ func (m *model) handleKeyHistory(msg tea.KeyMsg) (model, tea.Cmd) {
 	return *m, cmd
 }
 
+// handleKeyChangelogText handles keyboard input while editing the changelog text of a commit
+func (m *model) handleKeyChangelogText(msg tea.KeyMsg) (model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.state = stateHistory
+		return *m, nil
+	case "tab":
+		if m.entrySubject.Focused() {
+			m.entrySubject.Blur()
+			m.entryDesc.Focus()
+		} else {
+			m.entryDesc.Blur()
+			m.entrySubject.Focus()
+		}
+		return *m, nil
+	case "enter", "ctrl+s":
+		// enter starts a new line in the description
+		if msg.String() == "ctrl+s" || m.entrySubject.Focused() {
+			m.state = stateHistory
+			return *m, m.setChangelogText(m.entryHash, m.entrySubject.Value(), m.entryDesc.Value())
+		}
+	}
+	var cmd tea.Cmd
+	if m.entrySubject.Focused() {
+		m.entrySubject, cmd = m.entrySubject.Update(msg)
+	} else {
+		m.entryDesc, cmd = m.entryDesc.Update(msg)
+	}
+	return *m, cmd
+}
+
 // handleKeyRebasePrepare handles keyboard input in rebase prepare state
 func (m *model) handleKeyRebasePrepare(msg tea.KeyMsg) (model, tea.Cmd) {
 	switch msg.String() {
@@ -409,6 +823,19 @@ func (m *model) handleKeyCommit(msg tea.KeyMsg) (model, tea.Cmd) {
 		if m.manualImpact == "" {
 			m.decidedImpact = workspace.DetectImpact(m.commitMsg.Value())
 		}
+		m.focusCommitMessageIfNotMajor()
+		return *m, nil
+	case "tab":
+		// Migration notes are only asked for breaking changes
+		if m.decidedImpact == "major" {
+			if m.commitMsg.Focused() {
+				m.commitMsg.Blur()
+				m.migrationNotes.Focus()
+			} else {
+				m.migrationNotes.Blur()
+				m.commitMsg.Focus()
+			}
+		}
 		return *m, nil
 	case "enter":
 		if m.commitMsg.Value() != "" {
@@ -417,18 +844,32 @@ func (m *model) handleKeyCommit(msg tea.KeyMsg) (model, tea.Cmd) {
 		}
 	}
 
-	oldVal := m.commitMsg.Value()
 	var cmd tea.Cmd
+	if m.migrationNotes.Focused() {
+		m.migrationNotes, cmd = m.migrationNotes.Update(msg)
+		return *m, cmd
+	}
+
+	oldVal := m.commitMsg.Value()
 	m.commitMsg, cmd = m.commitMsg.Update(msg)
 	newVal := m.commitMsg.Value()
 
 	// update impact if NOT manual and message changed
 	if m.manualImpact == "" && oldVal != newVal {
 		m.decidedImpact = workspace.DetectImpact(newVal)
+		m.focusCommitMessageIfNotMajor()
 	}
 	return *m, cmd
 }
 
+// focusCommitMessageIfNotMajor moves focus back to the message when migration notes no longer apply
+func (m *model) focusCommitMessageIfNotMajor() {
+	if m.decidedImpact != "major" && m.migrationNotes.Focused() {
+		m.migrationNotes.Blur()
+		m.commitMsg.Focus()
+	}
+}
+
 // handleKeyNewWorkspace handles keyboard input in new workspace state
 func (m *model) handleKeyNewWorkspace(msg tea.KeyMsg) (model, tea.Cmd) {
 	switch msg.String() {
@@ -576,6 +1017,7 @@ func (m *model) handleKeyMain(msg tea.KeyMsg) (model, tea.Cmd) {
 		}
 	case "c":
 		m.state = stateCommit
+		m.migrationNotes.Blur()
 		m.commitMsg.Focus()
 	case "w":
 		m.state = stateWorkspaces
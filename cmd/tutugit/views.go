@@ -1,9 +1,13 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"strings"
+	"text/template"
+	"time"
 
+	"tutugit/internal/changelog"
 	"tutugit/internal/git"
 	"tutugit/internal/workspace"
 
@@ -17,6 +21,38 @@ func safeShortHash(h string) string {
 	return h
 }
 
+// relativeTime formats a date the way "git log --date=relative" does, for display only
+func relativeTime(t, now time.Time) string {
+	if t.IsZero() {
+		return "unknown date"
+	}
+	d := now.Sub(t)
+	if d < 0 {
+		return "in the future"
+	}
+
+	ago := func(n int, unit string) string {
+		return changelog.Plural(n, unit, unit+"s") + " ago"
+	}
+
+	day := 24 * time.Hour
+	switch {
+	case d < time.Minute:
+		return ago(int(d/time.Second), "second")
+	case d < time.Hour:
+		return ago(int(d/time.Minute), "minute")
+	case d < day:
+		return ago(int(d/time.Hour), "hour")
+	case d < 14*day:
+		return ago(int(d/day), "day")
+	case d < 60*day:
+		return ago(int(d/(7*day)), "week")
+	case d < 365*day:
+		return ago(int(d/(30*day)), "month")
+	}
+	return ago(int(d/(365*day)), "year")
+}
+
 func (m model) viewRebasePrepare() string {
 	s := m.renderHeader()
 	s += styleTitle.Render(" Interactive Rebase Planner ") + "\n\n"
@@ -202,6 +238,9 @@ func (m model) renderHeader() string {
 			header += " " + styleDim.Render(m.cfg.Project.Description)
 		}
 	}
+	if m.cfgErr != nil {
+		header += "\n" + styleAlert.Render(fmt.Sprintf("! %v (using the default settings)", m.cfgErr))
+	}
 
 	return header + "\n\n"
 }
@@ -337,6 +376,7 @@ func (m model) viewReflog() string {
 
 func (m *model) renderHistory() {
 	var b strings.Builder
+	now := time.Now()
 	for i, c := range m.commits {
 		marker := "○"
 		if len(c.Parents) > 1 {
@@ -353,7 +393,10 @@ func (m *model) renderHistory() {
 			styleBranch.Render(marker),
 			styleSelected.Render(c.ShortHash),
 			c.Message,
-			c.Date)
+			relativeTime(c.CommitterDate, now))
+		if m.meta != nil && m.meta.Hidden[c.Hash] {
+			line += " [hidden]"
+		}
 
 		if m.historyCursor == i {
 			b.WriteString(styleSelected.Render(line) + "\n")
@@ -365,6 +408,14 @@ func (m *model) renderHistory() {
 		if m.expandedHistory[c.Hash] {
 			b.WriteString(fmt.Sprintf("    %s Author: %s <%s>\n", styleAlert.Render(""), c.Author, c.Email))
 			b.WriteString(fmt.Sprintf("    %s Hash: %s\n", styleAlert.Render(""), c.Hash))
+			b.WriteString(fmt.Sprintf("    %s Date: %s\n", styleAlert.Render(""), c.AuthorDate.Format("2006-01-02 15:04:05 -0700")))
+			if m.meta != nil && m.meta.Subjects[c.Hash] != "" {
+				b.WriteString(fmt.Sprintf("    %s Changelog: %s\n", styleAlert.Render(""), m.meta.Subjects[c.Hash]))
+			}
+			if m.meta != nil && m.meta.Descriptions[c.Hash] != "" {
+				desc := strings.ReplaceAll(m.meta.Descriptions[c.Hash], "\n", "\n      ") // below the label
+				b.WriteString(fmt.Sprintf("    %s Description: %s\n", styleAlert.Render(""), desc))
+			}
 		} else if m.historyCursor == i {
 			b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render("    [Tip: Press Enter for details]") + "\n")
 		}
@@ -380,7 +431,23 @@ func (m model) viewHistory() string {
 	s := m.renderHeader()
 	s += styleTitle.Render(" Visual History ") + "\n"
 	s += m.historyViewport.View() + "\n"
-	s += "Shortcuts: [j/k/up/down] scroll | [enter] details | [R] interactive rebase | [L] summary | [esc/q/h] back\n"
+	s += "Shortcuts: [j/k/up/down] scroll | [enter] details | [x] hide from changelog | [e] changelog text | [R] interactive rebase | [L] summary | [esc/q/h] back\n"
+	return s
+}
+
+func (m model) viewChangelogText() string {
+	s := m.renderHeader()
+	s += styleTitle.Render(" Changelog Text ") + "\n\n"
+	for _, c := range m.commits {
+		if c.Hash == m.entryHash {
+			s += fmt.Sprintf("Commit: [%s] %s\n\n", styleSelected.Render(c.ShortHash), c.Message)
+		}
+	}
+	s += "Subject:\n"
+	s += m.entrySubject.View() + "\n\n"
+	s += "Description:\n"
+	s += m.entryDesc.View() + "\n\n"
+	s += "Shortcuts: [tab] switch field | [enter] save from the subject | [ctrl+s] save | [esc] cancel\n"
 	return s
 }
 
@@ -464,19 +531,217 @@ func (m model) viewCommit() string {
 	s += fmt.Sprintf("Impact: %s %s\n", impactLabel[m.decidedImpact], styleAlert.Render(info))
 
 	s += "\n" + m.commitMsg.View() + "\n\n"
+
+	// breaking changes carry migration notes into the release
+	if m.decidedImpact == "major" {
+		s += "Migration notes (breaking change):\n"
+		s += m.migrationNotes.View() + "\n\n"
+	}
+
 	s += "Tip: use prefixes like feat:, fix:, refactor: for auto-tagging\n"
-	s += "Shortcuts: [enter] commit | [alt+i] impact | [esc] cancel\n"
+	if m.decidedImpact == "major" {
+		s += "Shortcuts: [enter] commit | [tab] switch field | [alt+i] impact | [esc] cancel\n"
+	} else {
+		s += "Shortcuts: [enter] commit | [alt+i] impact | [esc] cancel\n"
+	}
 	return s
 }
 
+// collapsedRelease is the one-line summary of a collapsed release
+var collapsedRelease = template.Must(changelog.ParseTemplate("collapsed",
+	`Release {{.Version}} ({{maxImpact .Entries}}{{if not .Date.IsZero}}, {{date .Date}}{{end}}{{if .Recorded}}, recorded{{end}}): `+
+		`{{range $i, $c := tagCounts .Entries}}{{if $i}}, {{end}}{{$c.Count}} {{$c.Label}}{{end}}`))
+
+// renderSummary fills the summary viewport with the releases that pass the filters, one line
+// for the collapsed ones, and keeps the release under the cursor in sight
+func (m *model) renderSummary() {
+	rels := m.summaryFilter.Apply(m.summaryReleases)
+	m.summaryLines = nil
+	if len(rels) == 0 {
+		m.summaryCursor = 0
+		if m.summaryFilter.IsEmpty() {
+			m.summaryViewport.SetContent("No releases found.")
+		} else {
+			m.summaryViewport.SetContent("No changes match the filters. Press [x] to clear them.")
+		}
+		return
+	}
+	if m.summaryCursor >= len(rels) {
+		m.summaryCursor = len(rels) - 1
+	}
+
+	gen := m.newGenerator()
+	var b strings.Builder
+	for i, rel := range rels {
+		var text string
+		marker := "▾ "
+		switch {
+		case m.collapsed[rel.Version]:
+			var line strings.Builder
+			if err := collapsedRelease.Execute(&line, rel); err != nil {
+				line.WriteString("Release " + rel.Version)
+			}
+			text = line.String() + "\n\n"
+			marker = "▸ "
+		case m.summaryTemplate != nil:
+			out, err := gen.Render(m.summaryTemplate, []*changelog.Release{rel})
+			if err != nil {
+				out = fmt.Sprintf("Error rendering %s: %v\n", changelog.SummaryTemplate, err)
+			}
+			text = out
+		default:
+			text = gen.FormatSummary([]*changelog.Release{rel})
+		}
+
+		first, rest, _ := strings.Cut(text, "\n")
+		first = marker + first
+		if i == m.summaryCursor {
+			first = styleSelected.Render(first)
+		}
+		m.summaryLines = append(m.summaryLines, strings.Count(b.String(), "\n"))
+		b.WriteString(first + "\n" + rest)
+	}
+	m.summaryViewport.SetContent(b.String())
+
+	top := m.summaryLines[m.summaryCursor]
+	if top < m.summaryViewport.YOffset || top >= m.summaryViewport.YOffset+m.summaryViewport.Height {
+		m.summaryViewport.SetYOffset(top)
+	}
+}
+
+// summaryStatus describes the range and the filters applied to the summary, "" when there are none
+func (m model) summaryStatus() string {
+	var parts []string
+	if m.summaryPackage != "" {
+		parts = append(parts, "package: "+m.summaryPackage)
+	}
+	if m.hasSummaryRange() {
+		base, head := m.summaryBase.name, m.summaryHead.name
+		if base == "" {
+			base = "start"
+		}
+		if head == "" {
+			head = "HEAD"
+		}
+		parts = append(parts, "Range: "+base+".."+head)
+	}
+	f := m.summaryFilter
+	for _, filter := range []struct{ name, value string }{
+		{"tag", f.Tag}, {"workspace", f.Workspace}, {"impact", f.Impact}, {"author", f.Author}, {"search", f.Query},
+	} {
+		if filter.value != "" {
+			parts = append(parts, filter.name+": "+filter.value)
+		}
+	}
+	return strings.Join(parts, " | ")
+}
+
 func (m model) viewSummary() string {
 	s := m.renderHeader()
 	title := " Release Summary "
+	if m.currentLayout() == changelog.LayoutWorkspaces {
+		title = " Release Summary by Workspace "
+	}
 	if m.isUpdating {
 		title += "(Generating...)"
 	}
-	s += styleTitle.Render(title) + "\n"
+	s += styleTitle.Render(title)
+	if status := m.summaryStatus(); status != "" {
+		s += " " + styleAlert.Render(status)
+	}
+	s += "\n"
 	s += m.summaryViewport.View() + "\n"
-	s += "Shortcuts: [j/k/up/down] scroll | [E] export MD | [esc/q/L] back\n"
+	if m.searching {
+		s += m.summarySearch.View() + "  [enter] keep | [esc] clear\n"
+		return s
+	}
+	s += "Shortcuts: [j/k] release | [enter] collapse | [z] collapse all | [/] search | [t/w/i/a] filter by tag/workspace/impact/author | [x] clear filters | [r] range\n"
+	packages := ""
+	if len(changelog.PackageNames(m.cfg)) > 0 {
+		packages = "[P] package | "
+	}
+	s += fmt.Sprintf("           %s[V] release | [N] release notes | [R] record release | [E] export %s | [F] format | [C] update %s | [W] group by workspace/tag | [esc/q/L] back\n",
+		packages, strings.ToUpper(m.currentExportFormat()), m.newGenerator().ChangelogFile())
+	return s
+}
+
+func (m model) viewReleaseNotes() string {
+	s := m.renderHeader()
+	s += styleTitle.Render(" Release Notes ") + "\n\n"
+	target := "the next release"
+	if len(m.summaryReleases) > 0 && !m.summaryReleases[0].Recorded {
+		target = m.summaryReleases[0].Version
+	}
+	s += fmt.Sprintf("Intro and highlights of %s, shown above its entries in every export.\n", target)
+	s += "Write the intro first, then one highlight per line starting with \"- \".\n\n"
+	s += m.releaseNotes.View() + "\n\n"
+	s += "Shortcuts: [ctrl+s] save | [esc] cancel\n"
+	return s
+}
+
+func (m model) viewRelease() string {
+	s := m.renderHeader()
+	s += styleTitle.Render(" Release ") + "\n\n"
+	channel := "final"
+	if m.releaseChannel != "" {
+		channel = m.releaseChannel
+	}
+	if m.summaryPackage != "" {
+		s += fmt.Sprintf("Package: %s\n", styleSelected.Render(m.summaryPackage))
+	}
+	s += fmt.Sprintf("Channel: %s\n\n", styleSelected.Render(channel))
+	switch {
+	case m.isUpdating:
+		s += "Computing the next version...\n"
+	case errors.Is(m.releaseErr, changelog.ErrNothingToRelease):
+		s += "Nothing to release on this channel: no changes since the last release.\n"
+	case m.releaseErr != nil:
+		s += styleError.Render(m.releaseErr.Error()) + "\n"
+	case m.releasePlan != nil:
+		s += describePlan(m.releasePlan) + "\n\n"
+		if m.releaseConfirm {
+			s += styleError.Render(fmt.Sprintf("%s is a major release. Press [y] to confirm, any other key to cancel.", m.releasePlan.Tag)) + "\n"
+		} else if len(m.releasePlan.VersionFiles) > 0 {
+			s += fmt.Sprintf("Press [enter] to commit the version files and tag the commit as %s.\n", styleSelected.Render(m.releasePlan.Tag))
+		} else {
+			s += fmt.Sprintf("Press [enter] to tag HEAD as %s.\n", styleSelected.Render(m.releasePlan.Tag))
+		}
+	}
+	s += "\nShortcuts: [c] channel | [enter] tag release | [esc] back\n"
+	return s
+}
+
+func (m model) viewSummaryRange() string {
+	s := m.renderHeader()
+	first := "All releases (whole history)"
+	title := " Summary range: pick the base "
+	if m.pickingHead {
+		first = "HEAD (current checkout)"
+		title = fmt.Sprintf(" Summary range: pick the head (base %s) ", m.summaryBase.name)
+	}
+	s += styleTitle.Render(title) + "\n\n"
+
+	labels := []string{first}
+	for _, ref := range m.summaryRefs {
+		labels = append(labels, ref.label)
+	}
+	// only a window of the list fits on screen
+	visible := m.height - viewportReservedSpace - 2
+	if visible < 5 {
+		visible = 5
+	}
+	start := 0
+	if m.refCursor >= visible {
+		start = m.refCursor - visible + 1
+	}
+	for i := start; i < len(labels) && i < start+visible; i++ {
+		if i == m.refCursor {
+			s += styleSelected.Render("> "+labels[i]) + "\n"
+		} else {
+			s += "  " + labels[i] + "\n"
+		}
+	}
+
+	s += "\nShortcuts: [j/k/up/down] move | [enter] pick | [esc/q] back\n"
 	return s
 }
@@ -4,3 +4,6 @@ import "embed"
 
 //go:embed schemas/*
 var SchemasFS embed.FS
+
+//go:embed templates/*
+var TemplatesFS embed.FS
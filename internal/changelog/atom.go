@@ -0,0 +1,138 @@
+package changelog
+
+import (
+	"encoding/xml"
+	htmltemplate "html/template"
+	"strings"
+	"time"
+)
+
+// atomFeed -> an Atom 1.0 feed (RFC 4287), one entry per release.
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Author  atomPerson  `xml:"author"`
+	Links   []atomLink  `xml:"link,omitempty"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	ID      string       `xml:"id"`
+	Title   string       `xml:"title"`
+	Updated string       `xml:"updated"`
+	Authors []atomPerson `xml:"author,omitempty"`
+	Links   []atomLink   `xml:"link,omitempty"`
+	Content atomContent  `xml:"content"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+// ExportAtom -> produces an Atom feed with one entry per tagged release, rendered with the
+// HTML layout. Unreleased changes are left out, as feed entries are meant to stay put.
+func (g *Generator) ExportAtom(releases []*Release) ([]byte, error) {
+	return g.ExportAtomWith(nil, releases)
+}
+
+// ExportAtomWith -> ExportAtom with the entries rendered by the "release" block of an HTML
+// template override, as loaded by LoadTemplates. Without an override, or when it has no
+// such block, the built-in layout is used.
+func (g *Generator) ExportAtomWith(override Template, releases []*Release) ([]byte, error) {
+	var tmpl Template
+	if html, ok := override.(*htmltemplate.Template); ok {
+		if block := html.Lookup("release"); block != nil {
+			tmpl = block
+		}
+	}
+	if tmpl == nil {
+		builtin, err := htmlTemplate()
+		if err != nil {
+			return nil, err
+		}
+		tmpl = builtin.Lookup("release")
+	}
+
+	project := "Release Notes"
+	if g.Config != nil && g.Config.Project.Name != "" {
+		project = g.Config.Project.Name
+	}
+	feed := atomFeed{
+		ID:     "urn:tutugit:" + anchor(project),
+		Title:  project,
+		Author: atomPerson{Name: project},
+	}
+	// the remote resolved while the releases were generated
+	if g.remote != nil {
+		feed.ID = g.remote.BaseURL()
+		feed.Links = []atomLink{{Href: g.remote.BaseURL(), Rel: "alternate"}}
+	}
+
+	var updated time.Time
+	for _, rel := range releases {
+		if rel.Version == UnreleasedVersion {
+			continue
+		}
+		var content strings.Builder
+		if err := tmpl.Execute(&content, rel); err != nil {
+			return nil, err
+		}
+		date := releaseUpdated(rel)
+		entry := atomEntry{
+			ID:      feed.ID + "#" + anchor(rel.Version),
+			Title:   rel.Version,
+			Updated: date.UTC().Format(time.RFC3339),
+			Content: atomContent{Type: "html", Body: content.String()},
+		}
+		if rel.CompareURL != "" {
+			entry.Links = []atomLink{{Href: rel.CompareURL, Rel: "alternate"}}
+		}
+		for _, ct := range rel.Contributors {
+			entry.Authors = append(entry.Authors, atomPerson{Name: ct.Name})
+		}
+		feed.Entries = append(feed.Entries, entry)
+		if date.After(updated) {
+			updated = date
+		}
+	}
+	if updated.IsZero() {
+		updated = time.Now() // no release yet, the feed is as fresh as it gets
+	}
+	feed.Updated = updated.UTC().Format(time.RFC3339)
+
+	data, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
+
+// releaseUpdated -> when a release last changed: its date, else the newest of its commits
+// (a range ending on a branch or commit has no date), else now.
+func releaseUpdated(rel *Release) time.Time {
+	if !rel.Date.IsZero() {
+		return rel.Date
+	}
+	var newest time.Time
+	for _, e := range rel.Entries {
+		if e.Date.After(newest) {
+			newest = e.Date
+		}
+	}
+	if newest.IsZero() {
+		return time.Now()
+	}
+	return newest
+}
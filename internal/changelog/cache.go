@@ -0,0 +1,151 @@
+package changelog
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"tutugit/internal/git"
+)
+
+// cacheVersion -> bumped whenever the layout of cached releases changes.
+const cacheVersion = 10
+
+// releaseCache -> tagged releases computed by an earlier run. They stay valid while the
+// metadata of their commits, the settings and the tags they were computed from are unchanged.
+type releaseCache struct {
+	Version     int         `json:"version"`
+	Fingerprint string      `json:"fingerprint"` // hash of settings, remote and the metadata of Commits
+	Tags        []cachedTag `json:"tags"`        // newest first, one per release
+	Commits     []string    `json:"commits"`     // every commit of the releases, hidden ones included
+	Releases    []*Release  `json:"releases"`
+}
+
+// cachedTag -> a release tag and the commit it pointed to.
+type cachedTag struct {
+	Name string `json:"name"`
+	Hash string `json:"hash"`
+}
+
+// commitMeta -> the metadata of a commit that shapes its changelog entry.
+type commitMeta struct {
+	Tags                 []string `json:"tags,omitempty"`
+	Impact               string   `json:"impact,omitempty"`
+	Scope                string   `json:"scope,omitempty"`
+	Breaking             string   `json:"breaking,omitempty"`
+	Hidden               bool     `json:"hidden,omitempty"`
+	Subject              string   `json:"subject,omitempty"`
+	Description          string   `json:"description,omitempty"`
+	Workspace            string   `json:"workspace,omitempty"`
+	WorkspaceDescription string   `json:"workspace_description,omitempty"`
+}
+
+// cacheFingerprint -> identifies everything besides the tags that shapes the releases of
+// the commits: the settings, the remote, the recorded releases and the metadata of those
+// commits. Metadata of other commits, such as the ones not released yet, is left out so
+// editing it keeps the cache.
+func (g *Generator) cacheFingerprint(ctx context.Context, commits []string) string {
+	h := sha256.New()
+	enc := json.NewEncoder(h)
+	enc.Encode(cacheVersion)
+	enc.Encode(g.Config)
+	enc.Encode(g.Package)
+	enc.Encode(g.NoStats)
+	if remote := g.loadRemote(ctx); remote != nil {
+		enc.Encode(remote)
+	}
+	if g.Meta != nil {
+		enc.Encode(g.Meta.Releases)
+		for _, sha := range commits {
+			enc.Encode(g.commitMeta(sha))
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// commitMeta -> collects the metadata of one commit.
+func (g *Generator) commitMeta(sha string) commitMeta {
+	m := commitMeta{
+		Tags:        g.Meta.Tags[sha],
+		Impact:      g.Meta.Impacts[sha],
+		Scope:       g.Meta.Scopes[sha],
+		Breaking:    g.Meta.Breaking[sha],
+		Hidden:      g.Meta.Hidden[sha],
+		Subject:     g.Meta.Subjects[sha],
+		Description: g.Meta.Descriptions[sha],
+		Workspace:   g.workspaceOf(sha),
+	}
+	if m.Workspace != "" {
+		for _, ws := range g.Meta.Workspaces {
+			if ws.Name == m.Workspace {
+				m.WorkspaceDescription = ws.Description
+				break
+			}
+		}
+	}
+	return m
+}
+
+// loadCache -> returns the releases cached for the oldest tags, the commits they were built
+// from, and the index of the first of those tags. When nothing can be reused, the index is
+// len(tags).
+func (g *Generator) loadCache(ctx context.Context, tags []git.TagRef) (int, []*Release, []string) {
+	if g.CachePath == "" {
+		return len(tags), nil, nil
+	}
+	data, err := os.ReadFile(g.CachePath)
+	if err != nil {
+		return len(tags), nil, nil
+	}
+	var cache releaseCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return len(tags), nil, nil
+	}
+	if cache.Version != cacheVersion || len(cache.Tags) != len(cache.Releases) || len(cache.Tags) > len(tags) ||
+		cache.Fingerprint != g.cacheFingerprint(ctx, cache.Commits) {
+		return len(tags), nil, nil
+	}
+
+	// the cached tags must be exactly the oldest tags of today, pointing at the same commits
+	from := len(tags) - len(cache.Tags)
+	for i, ct := range cache.Tags {
+		if tags[from+i].Name != ct.Name || tags[from+i].Hash != ct.Hash {
+			return len(tags), nil, nil
+		}
+	}
+	return from, cache.Releases, cache.Commits
+}
+
+// saveCache -> stores the tagged releases and the commits they were built from. Caching is
+// best effort, so errors are ignored.
+func (g *Generator) saveCache(ctx context.Context, tags []git.TagRef, releases []*Release, commits []string) {
+	if g.CachePath == "" || len(tags) != len(releases) {
+		return
+	}
+	cache := releaseCache{
+		Version:     cacheVersion,
+		Fingerprint: g.cacheFingerprint(ctx, commits),
+		Commits:     commits,
+		Releases:    releases,
+	}
+	for _, t := range tags {
+		cache.Tags = append(cache.Tags, cachedTag{Name: t.Name, Hash: t.Hash})
+	}
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return
+	}
+	dir := filepath.Dir(g.CachePath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return
+	}
+	// the cache is local to each clone and must never be committed with .tutugit
+	ignore := filepath.Join(dir, ".gitignore")
+	if _, err := os.Stat(ignore); os.IsNotExist(err) {
+		os.WriteFile(ignore, []byte("*\n"), 0644)
+	}
+	os.WriteFile(g.CachePath, data, 0644)
+}
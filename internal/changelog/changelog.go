@@ -3,35 +3,104 @@ package changelog
 import (
 	"context"
 	"encoding/json"
-	"fmt"
-	"strings"
+	"time"
+	"tutugit/internal/config"
 	"tutugit/internal/git"
 	"tutugit/internal/workspace"
 )
 
+// UnreleasedVersion -> the version name of changes not yet covered by a tag.
+const UnreleasedVersion = "Unreleased"
+
 // ChangeEntry -> represents a single normalized change in the history.
 type ChangeEntry struct {
-	Hash      string `json:"hash"`
-	ShortHash string `json:"short_hash"`
-	Author    string `json:"author"`
-	Subject   string `json:"subject"`
-	Tag       string `json:"tag"`
-	Impact    string `json:"impact"`
-	Workspace string `json:"workspace,omitempty"`
-	Date      string `json:"date"`
+	Hash        string     `json:"hash"`
+	ShortHash   string     `json:"short_hash"`
+	Author      string     `json:"author"`
+	Email       string     `json:"email,omitempty"` // canonical email of the author, mailmap applied
+	Subject     string     `json:"subject"`
+	Tag         string     `json:"tag"`
+	Scope       string     `json:"scope,omitempty"`
+	Impact      string     `json:"impact"`
+	Workspace   string     `json:"workspace,omitempty"`
+	Packages    []string   `json:"packages,omitempty"` // monorepo packages whose files the commit touches
+	Breaking    string     `json:"breaking,omitempty"` // description or migration notes of a breaking change
+	Reverts     string     `json:"reverts,omitempty"`  // SHA of the commit this entry reverts
+	Issues      []IssueRef `json:"issues,omitempty"`
+	URL         string     `json:"url,omitempty"`         // web page of the commit on the forge
+	RevertsURL  string     `json:"reverts_url,omitempty"` // web page of the reverted commit
+	Date        time.Time  `json:"date"`                  // when the commit landed (committer date)
+	Description string     `json:"description,omitempty"` // longer text written for the changelog
+	Stats       *DiffStats `json:"stats,omitempty"`       // files and lines changed by the commit
+
+	// set on merges in first-parent mode
+	PullRequest    string        `json:"pull_request,omitempty"` // e.g. "#42", or "!42" on GitLab
+	PullRequestURL string        `json:"pull_request_url,omitempty"`
+	Branch         string        `json:"branch,omitempty"`  // merged branch
+	Commits        []ChangeEntry `json:"commits,omitempty"` // commits the merge brought in
+}
+
+// BreakingChange -> a breaking change surfaced at the top of a release.
+type BreakingChange struct {
+	Hash        string `json:"hash"`
+	ShortHash   string `json:"short_hash"`
+	Subject     string `json:"subject"`
+	Description string `json:"description"`
+	URL         string `json:"url,omitempty"`
 }
 
 // Release -> represents a versioned collection of changes.
 type Release struct {
-	Version string        `json:"version"`
-	Date    string        `json:"date"`
-	Entries []ChangeEntry `json:"entries"`
+	Version         string           `json:"version"`
+	Date            time.Time        `json:"date"`                  // tag creation date, or the newest commit when unreleased
+	Previous        string           `json:"previous,omitempty"`    // revision of the previous release, if any
+	Intro           string           `json:"intro,omitempty"`       // hand-written paragraph shown above the entries
+	Highlights      []string         `json:"highlights,omitempty"`  // hand-written headline changes
+	CompareURL      string           `json:"compare_url,omitempty"` // forge page comparing this release with the previous one
+	BreakingChanges []BreakingChange `json:"breaking_changes,omitempty"`
+	Entries         []ChangeEntry    `json:"entries"`
+	Reverted        []ChangeEntry    `json:"reverted,omitempty"`   // reverts of changes shipped in earlier releases
+	Workspaces      []WorkspaceInfo  `json:"workspaces,omitempty"` // workspaces the entries belong to, sorted by name
+	Contributors    []Contributor    `json:"contributors,omitempty"`
+	Stats           *DiffStats       `json:"stats,omitempty"`    // size of the release, merges aside
+	Recorded        bool             `json:"recorded,omitempty"` // frozen in the metadata when it was published
+}
+
+// WorkspaceInfo -> a logical workspace as presented in release notes.
+type WorkspaceInfo struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	Stats       *DiffStats `json:"stats,omitempty"`
 }
 
 // Generator -> orchestrates the creation of release data from git and tutugit metadata.
 type Generator struct {
-	Git  git.GitProvider
-	Meta *workspace.Meta
+	Git    git.GitProvider
+	Meta   *workspace.Meta
+	Config *config.Config // optional, project settings such as issue patterns
+
+	// Package limits the releases to one package of a monorepo, see ForPackage.
+	Package *config.Package
+
+	// Layout overrides the configured layout of the built-in templates, e.g. when it is
+	// switched in the summary view.
+	Layout string
+
+	// Version is the version of tutugit, recorded in JSON exports.
+	Version string
+
+	// NoStats leaves the sizes of entries, releases and workspaces out when they aren't
+	// shown, so git only lists the files of commits when exclusions or packages need them.
+	NoStats bool
+
+	// CachePath is an optional file where tagged releases are cached between runs.
+	// Its directory is kept out of git.
+	CachePath string
+
+	remote         *Remote
+	remoteLoaded   bool
+	workspaceIndex map[string]string // commit SHA -> workspace name
+	now            func() time.Time  // clock of CalVer versions, time.Now when nil
 }
 
 // NewGenerator -> creates a new generator.
@@ -39,6 +108,39 @@ func NewGenerator(g git.GitProvider, m *workspace.Meta) *Generator {
 	return &Generator{Git: g, Meta: m}
 }
 
+// loadRemote -> resolves the "origin" remote once per generator. Returns nil without a usable remote.
+func (g *Generator) loadRemote(ctx context.Context) *Remote {
+	if !g.remoteLoaded {
+		g.remoteLoaded = true
+		if url, err := g.Git.GetRemoteURL(ctx); err == nil {
+			g.remote = ParseRemote(url)
+		}
+		if g.remote != nil && g.Config != nil && g.Config.Changelog.Forge != "" {
+			g.remote.Kind = g.Config.Changelog.Forge
+		}
+	}
+	return g.remote
+}
+
+// workspaceOf -> the name of the workspace a commit belongs to, from an index built once
+// per generator so large histories don't rescan every workspace for every commit.
+func (g *Generator) workspaceOf(hash string) string {
+	if g.workspaceIndex == nil {
+		g.workspaceIndex = make(map[string]string)
+		if g.Meta != nil {
+			for _, ws := range g.Meta.Workspaces {
+				for _, h := range ws.Commits {
+					// first workspace wins, as commits may be listed in several
+					if _, ok := g.workspaceIndex[h]; !ok {
+						g.workspaceIndex[h] = ws.Name
+					}
+				}
+			}
+		}
+	}
+	return g.workspaceIndex[hash]
+}
+
 // GenerateRelease -> collects commits for a specific range and returns a Release.
 func (g *Generator) GenerateRelease(ctx context.Context, version, base, head string) (*Release, error) {
 	commits, err := g.Git.GetCommitsInRange(ctx, base, head)
@@ -46,14 +148,71 @@ func (g *Generator) GenerateRelease(ctx context.Context, version, base, head str
 		return nil, err
 	}
 
+	var date time.Time
+	if version != UnreleasedVersion {
+		date, _ = g.Git.GetTagDate(ctx, version)
+	}
+	rel, err := g.buildRelease(ctx, version, base, head, date, commits, nil)
+	if err != nil {
+		return nil, err
+	}
+	if base != "" {
+		markFirstTimers([]*Release{rel}, g.knownAuthors(ctx, base))
+	}
+	return rel, nil
+}
+
+// buildRelease -> turns the commits of a release (oldest first) into entries, leaving out the
+// ones in skip. A zero date falls back to the date of the newest commit.
+func (g *Generator) buildRelease(ctx context.Context, version, base, head string, date time.Time, commits []git.Commit, skip map[string]bool) (*Release, error) {
+	remote := g.loadRemote(ctx)
+	patterns := issuePatterns(g.Config, remote)
+	firstParent := g.firstParent()
+	exclude := newExclusions(g.Config, g.Meta)
+	fileStats, err := g.commitStats(ctx, commits)
+	if err != nil {
+		return nil, err
+	}
+	sizes := fileStats // the stats shown, the files touched may be needed regardless
+	if g.NoStats {
+		sizes = nil
+	} else if g.Package != nil {
+		sizes = packageStats(g.Package, fileStats)
+	}
+
 	var entries []ChangeEntry
+	var subjects []string // the commit subject of each entry, before the changelog overrides
+	var credited []git.Commit
+	reverts := make(map[int]revertRef)
 	for _, c := range commits {
+		if skip[c.Hash] {
+			continue
+		}
+		// footers such as "BREAKING CHANGE:" live in the full message
+		fullMessage := c.Message
+		if c.Body != "" {
+			fullMessage = c.Body
+		}
+
 		entry := ChangeEntry{
 			Hash:      c.Hash,
 			ShortHash: c.ShortHash,
 			Author:    c.Author,
 			Subject:   c.Message,
-			Date:      c.Date,
+			Date:      c.CommitterDate,
+		}
+
+		// in first-parent mode a merge stands for its pull request, described by its title
+		if firstParent && len(c.Parents) > 1 {
+			info := parseMerge(fullMessage)
+			entry.Branch = info.branch
+			entry.PullRequest = info.pullRequest
+			if info.title != "" {
+				entry.Subject = info.title
+			}
+			if remote != nil && info.pullRequest != "" {
+				entry.PullRequestURL = remote.PullRequestURL(info.pullRequest[1:])
+			}
 		}
 
 		// associate with tag (nil-safe)
@@ -61,256 +220,370 @@ func (g *Generator) GenerateRelease(ctx context.Context, version, base, head str
 			if tags, ok := g.Meta.Tags[c.Hash]; ok && len(tags) > 0 {
 				entry.Tag = tags[0]
 			} else {
-				entry.Tag = workspace.DetectTag(c.Message)
+				entry.Tag = workspace.DetectTag(entry.Subject)
 			}
 		} else {
-			entry.Tag = workspace.DetectTag(c.Message)
+			entry.Tag = workspace.DetectTag(entry.Subject)
 		}
 
-		// associate with workspace (nil-safe)
+		files := statPaths(fileStats[c.Hash])
+		if exclude.excludes(c, fullMessage, entry.Tag, files) {
+			continue
+		}
+		if g.Package != nil && !inPackage(g.Package, files) {
+			continue
+		}
+		credited = append(credited, c)
+		_, entry.Email = g.identity(c.Author, c.Email)
+
+		// associate with scope (nil-safe)
+		if g.Meta != nil && g.Meta.Scopes != nil {
+			if scope, ok := g.Meta.Scopes[c.Hash]; ok {
+				entry.Scope = scope
+			} else {
+				entry.Scope = workspace.DetectScope(entry.Subject)
+			}
+		} else {
+			entry.Scope = workspace.DetectScope(entry.Subject)
+		}
+
+		// text written for the changelog replaces the commit subject; the type and scope
+		// still come from the commit
 		if g.Meta != nil {
-			for _, ws := range g.Meta.Workspaces {
-				for _, h := range ws.Commits {
-					if h == c.Hash {
-						entry.Workspace = ws.Name
-						break
-					}
-				}
-				if entry.Workspace != "" {
-					break
-				}
+			if subject := g.Meta.Subjects[c.Hash]; subject != "" {
+				entry.Subject = subject
 			}
+			entry.Description = g.Meta.Descriptions[c.Hash]
 		}
 
+		// associate with workspace (nil-safe)
+		entry.Workspace = g.workspaceOf(c.Hash)
+		entry.Stats = entryStats(sizes[c.Hash])
+		entry.Packages = packagesOf(g.Config, files)
+
 		// associate with impact (nil-safe)
 		if g.Meta != nil && g.Meta.Impacts != nil {
 			if level, ok := g.Meta.Impacts[c.Hash]; ok {
 				entry.Impact = level
 			} else {
-				entry.Impact = workspace.DetectImpact(c.Message)
+				entry.Impact = workspace.DetectImpact(fullMessage)
 			}
 		} else {
-			entry.Impact = workspace.DetectImpact(c.Message)
+			entry.Impact = workspace.DetectImpact(fullMessage)
+		}
+
+		// describe breaking changes: migration notes typed by the author win over the footer
+		if entry.Impact == "major" {
+			if g.Meta != nil && g.Meta.Breaking[c.Hash] != "" {
+				entry.Breaking = g.Meta.Breaking[c.Hash]
+			} else if desc := workspace.DetectBreaking(fullMessage); desc != "" {
+				entry.Breaking = desc
+			} else {
+				entry.Breaking = c.Message
+			}
+		}
+
+		entry.Issues = extractIssues(patterns, entry.Subject, fullMessage)
+
+		// remember what reverts point at, they are resolved once the whole range is known
+		if isRevert, sha, subject := workspace.DetectRevert(fullMessage); isRevert {
+			entry.Reverts = sha
+			reverts[len(entries)] = revertRef{sha: sha, subject: subject}
+		}
+
+		// link back to the forge
+		if remote != nil {
+			entry.URL = remote.CommitURL(c.Hash)
+			if entry.Reverts != "" {
+				entry.RevertsURL = remote.CommitURL(entry.Reverts)
+			}
 		}
 
 		entries = append(entries, entry)
+		subjects = append(subjects, c.Message)
+	}
+
+	entries, reverted := applyReverts(entries, subjects, reverts)
+
+	var breaking []BreakingChange
+	for _, e := range entries {
+		if e.Breaking != "" {
+			breaking = append(breaking, BreakingChange{
+				Hash:        e.Hash,
+				ShortHash:   e.ShortHash,
+				Subject:     e.Subject,
+				Description: e.Breaking,
+				URL:         e.URL,
+			})
+		}
 	}
 
-	date := ""
-	if len(commits) > 0 {
-		date = commits[len(commits)-1].Date
+	if firstParent {
+		entries = nestMerges(entries, commits, g.Config.Changelog.HideMergedCommits)
+	}
+
+	// a release is dated by its tag; unreleased changes by the newest commit
+	if date.IsZero() && len(commits) > 0 {
+		date = commits[len(commits)-1].CommitterDate
+	}
+
+	compareURL := ""
+	if remote != nil && base != "" {
+		compareURL = remote.CompareURL(base, head)
 	}
 
 	return &Release{
-		Version: version,
-		Date:    date,
-		Entries: entries,
+		Version:         version,
+		Date:            date,
+		Previous:        base,
+		CompareURL:      compareURL,
+		BreakingChanges: breaking,
+		Entries:         entries,
+		Reverted:        reverted,
+		Workspaces:      g.releaseWorkspaces(entries, credited, sizes),
+		Contributors:    g.contributors(credited),
+		Stats:           releaseStats(credited, sizes, nil),
 	}, nil
 }
 
-// GenerateFull -> iterates through all tags to produce a complete release history.
-func (g *Generator) GenerateFull(ctx context.Context) ([]*Release, error) {
-	tags, err := g.Git.GetTags(ctx)
+// releaseWorkspaces -> the workspaces touched by the entries, nested commits included,
+// with their descriptions and the size of their changes.
+func (g *Generator) releaseWorkspaces(entries []ChangeEntry, commits []git.Commit, fileStats map[string][]git.FileStat) []WorkspaceInfo {
+	all := entries
+	for _, e := range entries {
+		all = append(all, e.Commits...)
+	}
+	var infos []WorkspaceInfo
+	for _, name := range workspaceNames(all) {
+		info := WorkspaceInfo{Name: name}
+		info.Stats = releaseStats(commits, fileStats, func(hash string) bool { return g.workspaceOf(hash) == name })
+		if g.Meta != nil {
+			for _, ws := range g.Meta.Workspaces {
+				if ws.Name == name {
+					info.Description = ws.Description
+					break
+				}
+			}
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// ReleaseTags -> the tags that mark releases of the current history, newest first: tags
+// reachable from HEAD that match the configured patterns, ordered by semantic version.
+// Pre-releases are folded into the final release that followed them.
+func (g *Generator) ReleaseTags(ctx context.Context) ([]VersionTag, error) {
+	tags, err := g.Git.GetReachableTags(ctx, "HEAD")
 	if err != nil {
 		return nil, err
 	}
+	skipPrereleases := g.Config != nil && g.Config.Changelog.SkipPrereleases
+	return foldPrereleases(SelectVersionTags(tags, g.tagPatterns(), skipPrereleases)), nil
+}
 
-	var releases []*Release
+// GenerateFull -> produces the complete release history in a single walk of the commit
+// graph: every commit is assigned to the oldest release tag that contains it, and commits
+// no tag contains are unreleased. Tagged releases already in the cache are reused, and
+// only the history after the newest cached tag is read. Releases recorded in the metadata
+// take the place of their computed counterparts, the commits they shipped are left out of
+// the other releases, and the upcoming release gets the notes written for it.
+func (g *Generator) GenerateFull(ctx context.Context) ([]*Release, error) {
+	versionTags, err := g.ReleaseTags(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	if len(tags) == 0 {
-		rel, err := g.GenerateRelease(ctx, "Unreleased", "", "HEAD")
-		if err == nil && len(rel.Entries) > 0 {
+	if len(versionTags) == 0 {
+		var releases []*Release
+		commits, err := g.Git.GetHistory(ctx, "HEAD", nil)
+		if err != nil {
+			return nil, err
+		}
+		reverseCommits(commits)
+		rel, err := g.buildRelease(ctx, UnreleasedVersion, "", "HEAD", time.Time{}, commits, g.shippedCommits())
+		if err != nil {
+			return nil, err
+		}
+		if len(rel.Entries) > 0 {
 			releases = append(releases, rel)
 		}
-		return releases, nil
+		return g.applyUpcoming(g.applyRecorded(releases)), nil
 	}
 
-	headRel, err := g.GenerateRelease(ctx, "Unreleased", tags[0], "HEAD")
-	if err == nil && len(headRel.Entries) > 0 {
-		releases = append(releases, headRel)
+	refs, err := g.Git.GetTagRefs(ctx)
+	if err != nil {
+		return nil, err
 	}
-
-	for i := 0; i < len(tags)-1; i++ {
-		rel, err := g.GenerateRelease(ctx, tags[i], tags[i+1], tags[i])
-		if err == nil {
-			releases = append(releases, rel)
-		}
+	tags := make([]git.TagRef, len(versionTags))
+	byName := make(map[string]git.TagRef, len(refs))
+	for _, r := range refs {
+		byName[r.Name] = r
 	}
-
-	if len(tags) > 0 {
-		firstRel, err := g.GenerateRelease(ctx, tags[len(tags)-1], "", tags[len(tags)-1])
-		if err == nil {
-			releases = append(releases, firstRel)
-		}
+	for i, vt := range versionTags {
+		tags[i] = git.TagRef{Name: vt.Name, Hash: byName[vt.Name].Hash, Date: byName[vt.Name].Date}
 	}
 
-	return releases, nil
-}
-
-// FormatSummary -> produces a clean, structured release summary.
-func (g *Generator) FormatSummary(releases []*Release) string {
-	if len(releases) == 0 {
-		return "No releases found."
+	// everything reachable from the newest cached tag is already known
+	cachedFrom, cached, cachedCommits := g.loadCache(ctx, tags)
+	var exclude []string
+	if cachedFrom < len(tags) {
+		exclude = []string{tags[cachedFrom].Name}
 	}
 
-	var b strings.Builder
+	commits, err := g.Git.GetHistory(ctx, "HEAD", exclude)
+	if err != nil {
+		return nil, err
+	}
+	buckets := assignReleases(commits, tags[:cachedFrom])
 
-	for _, rel := range releases {
-		// Count by tag
-		counts := make(map[string]int)
-		maxImpact := "patch"
-		impactWeight := map[string]int{"patch": 0, "minor": 1, "major": 2}
-
-		for _, e := range rel.Entries {
-			tag := e.Tag
-			if tag == "" || tag == "none" {
-				tag = "other"
-			}
-			counts[tag]++
-			if impactWeight[e.Impact] > impactWeight[maxImpact] {
-				maxImpact = e.Impact
-			}
+	shipped := g.shippedCommits()
+	unreleased, err := g.buildRelease(ctx, UnreleasedVersion, tags[0].Name, "HEAD", time.Time{}, buckets[-1], shipped)
+	if err != nil {
+		return nil, err
+	}
+	var computed []*Release
+	var tagged []string // commits of the tagged releases, for the cache
+	for i := 0; i < cachedFrom; i++ {
+		base := ""
+		if i+1 < len(tags) {
+			base = tags[i+1].Name
 		}
-
-		// Header
-		b.WriteString(fmt.Sprintf("Release %s\n", rel.Version))
-		b.WriteString(fmt.Sprintf("Impact: %s\n", maxImpact))
-
-		// Change counts
-		var parts []string
-		order := []struct{ tag, label string }{
-			{"feature", "features"},
-			{"fix", "fixes"},
-			{"refactor", "refactors"},
-			{"experiment", "experiments"},
-			{"other", "other"},
+		rel, err := g.buildRelease(ctx, tags[i].Name, base, tags[i].Name, tags[i].Date, buckets[i], shipped)
+		if err != nil {
+			return nil, err
 		}
-		for _, o := range order {
-			if c, ok := counts[o.tag]; ok && c > 0 {
-				parts = append(parts, fmt.Sprintf("%d %s", c, o.label))
-			}
+		computed = append(computed, rel)
+		for _, c := range buckets[i] {
+			tagged = append(tagged, c.Hash)
 		}
-		b.WriteString(fmt.Sprintf("Changes: %s\n", strings.Join(parts, ", ")))
+	}
 
-		// Workspace (if consistent)
-		wsSet := make(map[string]bool)
-		for _, e := range rel.Entries {
-			if e.Workspace != "" {
-				wsSet[e.Workspace] = true
-			}
+	// contributors are new when no earlier release credits them; cached releases are final
+	known := make(map[string]bool)
+	for _, rel := range cached {
+		for _, ct := range rel.Contributors {
+			known[ct.Email] = true
 		}
-		if len(wsSet) == 1 {
-			for ws := range wsSet {
-				b.WriteString(fmt.Sprintf("Workspace: %s\n", ws))
-			}
-		} else if len(wsSet) > 1 {
-			var names []string
-			for ws := range wsSet {
-				names = append(names, ws)
-			}
-			b.WriteString(fmt.Sprintf("Workspaces: %s\n", strings.Join(names, ", ")))
+	}
+	fresh := []*Release{unreleased}
+	for _, rel := range computed {
+		fresh = append([]*Release{rel}, fresh...)
+	}
+	if len(cached) == 0 {
+		// nobody is new in the very first release
+		for _, ct := range fresh[0].Contributors {
+			known[ct.Email] = true
 		}
+		fresh = fresh[1:]
+	}
+	markFirstTimers(fresh, known)
 
-		b.WriteString("───────────────────────\n")
+	var releases []*Release
+	if len(unreleased.Entries) > 0 {
+		releases = append(releases, unreleased)
+	}
+	releases = append(releases, computed...)
+	releases = append(releases, cached...)
+
+	g.saveCache(ctx, tags, append(computed, cached...), append(tagged, cachedCommits...))
+	return g.applyUpcoming(g.applyRecorded(releases)), nil
+}
+
+// assignReleases -> splits a newest-first, topologically ordered history between release tags
+// (newest first). Each commit goes to the oldest tag it is reachable from, which is propagated
+// from children to parents; -1 collects the commits no tag reaches. Buckets are oldest first.
+func assignReleases(commits []git.Commit, tags []git.TagRef) map[int][]git.Commit {
+	tagIndex := make(map[string]int)
+	for i, t := range tags {
+		// the oldest tag wins when several point at the same commit
+		tagIndex[t.Hash] = i
+	}
 
-		// Entries
-		for _, e := range rel.Entries {
-			tag := e.Tag
-			if tag == "" || tag == "none" {
-				tag = "other"
+	label := make(map[string]int, len(commits))
+	buckets := make(map[int][]git.Commit)
+	for _, c := range commits {
+		l, ok := label[c.Hash]
+		if !ok {
+			l = -1
+		}
+		if i, tagged := tagIndex[c.Hash]; tagged && i > l {
+			l = i
+		}
+		buckets[l] = append(buckets[l], c)
+		for _, p := range c.Parents {
+			if prev, seen := label[p]; !seen || l > prev {
+				label[p] = l
 			}
-			// Pad tag for alignment
-			padded := fmt.Sprintf("%-10s", tag+":")
-			b.WriteString(fmt.Sprintf("  %s %s (%s)\n", padded, e.Subject, e.ShortHash))
 		}
-		b.WriteString("\n")
 	}
 
-	return b.String()
-}
-
-// ExportJSON -> produces structured JSON for external tool consumption.
-func (g *Generator) ExportJSON(releases []*Release) ([]byte, error) {
-	return json.MarshalIndent(releases, "", "  ")
+	for _, list := range buckets {
+		reverseCommits(list)
+	}
+	return buckets
 }
 
-// ExportMarkdown -> produces a human-readable Markdown summary.
-func (g *Generator) ExportMarkdown(releases []*Release) string {
-	if len(releases) == 0 {
-		return "# Release Summary\n\nNo releases found."
+// reverseCommits -> reverses a list of commits in place.
+func reverseCommits(commits []git.Commit) {
+	for i, j := 0, len(commits)-1; i < j; i, j = i+1, j-1 {
+		commits[i], commits[j] = commits[j], commits[i]
 	}
+}
 
-	var b strings.Builder
-	b.WriteString("# Release Summary\n\n")
+// FormatSummary -> produces a clean, structured release summary.
+func (g *Generator) FormatSummary(releases []*Release) string {
+	return g.renderBuiltin(SummaryTemplate, releases)
+}
 
-	for _, rel := range releases {
-		// Count by tag
-		counts := make(map[string]int)
-		maxImpact := "patch"
-		impactWeight := map[string]int{"patch": 0, "minor": 1, "major": 2}
-
-		for _, e := range rel.Entries {
-			tag := e.Tag
-			if tag == "" || tag == "none" {
-				tag = "other"
-			}
-			counts[tag]++
-			if impactWeight[e.Impact] > impactWeight[maxImpact] {
-				maxImpact = e.Impact
-			}
-		}
+// JSONSchemaVersion -> the version of the JSON export format, described by
+// internal/assets/schemas/release.schema.json. Bump it when a field is removed or changes meaning.
+const JSONSchemaVersion = 1
 
-		// Release Header
-		b.WriteString(fmt.Sprintf("## %s\n", rel.Version))
-		b.WriteString(fmt.Sprintf("- **Impact:** %s\n", maxImpact))
-
-		// Change counts
-		var parts []string
-		order := []struct{ tag, label string }{
-			{"feature", "features"},
-			{"fix", "fixes"},
-			{"refactor", "refactors"},
-			{"experiment", "experiments"},
-			{"other", "other"},
-		}
-		for _, o := range order {
-			if c, ok := counts[o.tag]; ok && c > 0 {
-				parts = append(parts, fmt.Sprintf("%d %s", c, o.label))
-			}
-		}
-		b.WriteString(fmt.Sprintf("- **Changes:** %s\n", strings.Join(parts, ", ")))
+// JSONExport -> the document written by ExportJSON.
+type JSONExport struct {
+	SchemaVersion int           `json:"schema_version"`
+	Project       JSONProject   `json:"project"`
+	Generator     JSONGenerator `json:"generator"`
+	Releases      []*Release    `json:"releases"`
+}
 
-		// Workspace (if consistent)
-		wsSet := make(map[string]bool)
-		for _, e := range rel.Entries {
-			if e.Workspace != "" {
-				wsSet[e.Workspace] = true
-			}
-		}
-		if len(wsSet) == 1 {
-			for ws := range wsSet {
-				b.WriteString(fmt.Sprintf("- **Workspace:** %s\n", ws))
-			}
-		} else if len(wsSet) > 1 {
-			var names []string
-			for ws := range wsSet {
-				names = append(names, ws)
-			}
-			b.WriteString(fmt.Sprintf("- **Workspaces:** %s\n", strings.Join(names, ", ")))
-		}
+// JSONProject -> the project a JSON export describes.
+type JSONProject struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
 
-		b.WriteString("\n---\n\n")
+// JSONGenerator -> the tool that produced a JSON export.
+type JSONGenerator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
 
-		// Entries
-		for _, e := range rel.Entries {
-			tag := e.Tag
-			if tag == "" || tag == "none" {
-				tag = "other"
-			}
-			b.WriteString(fmt.Sprintf("- **%s:** %s (`%s`)\n", tag, e.Subject, e.ShortHash))
+// ExportJSON -> produces structured JSON for external tool consumption: a versioned
+// envelope around the releases, see JSONExport.
+func (g *Generator) ExportJSON(releases []*Release) ([]byte, error) {
+	doc := JSONExport{
+		SchemaVersion: JSONSchemaVersion,
+		Generator:     JSONGenerator{Name: "tutugit", Version: g.Version},
+		Releases:      make([]*Release, 0, len(releases)),
+	}
+	if g.Config != nil {
+		doc.Project = JSONProject{Name: g.Config.Project.Name, Description: g.Config.Project.Description}
+	}
+	for _, rel := range releases {
+		// lists the schema requires are written as [] rather than null
+		if rel.Entries == nil {
+			copied := *rel
+			copied.Entries = []ChangeEntry{}
+			rel = &copied
 		}
-		b.WriteString("\n")
+		doc.Releases = append(doc.Releases, rel)
 	}
+	return json.MarshalIndent(doc, "", "  ")
+}
 
-	return b.String()
+// ExportMarkdown -> produces a human-readable Markdown summary.
+func (g *Generator) ExportMarkdown(releases []*Release) string {
+	return g.renderBuiltin(MarkdownTemplate, releases)
 }
@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"strings"
 	"testing"
+	"time"
 
+	"tutugit/internal/config"
 	"tutugit/internal/git"
 	"tutugit/internal/workspace"
 )
@@ -72,16 +74,21 @@ func TestExportJSON(t *testing.T) {
 		},
 	}
 
-	g := &Generator{}
+	g := &Generator{Version: "1.2.3", Config: &config.Config{Project: config.Project{Name: "acme"}}}
 	data, err := g.ExportJSON([]*Release{rel})
 	if err != nil {
 		t.Fatalf("ExportJSON failed: %v", err)
 	}
 
-	var parsed []Release
-	if err := json.Unmarshal(data, &parsed); err != nil {
+	var doc JSONExport
+	if err := json.Unmarshal(data, &doc); err != nil {
 		t.Fatalf("Invalid JSON output: %v", err)
 	}
+	if doc.SchemaVersion != JSONSchemaVersion || doc.Project.Name != "acme" || doc.Generator != (JSONGenerator{Name: "tutugit", Version: "1.2.3"}) {
+		t.Errorf("Unexpected envelope: %+v", doc)
+	}
+
+	parsed := doc.Releases
 
 	if len(parsed) != 1 {
 		t.Fatalf("Expected 1 release, got %d", len(parsed))
@@ -212,3 +219,222 @@ func TestGenerateFull_EmptyCommits(t *testing.T) {
 		t.Errorf("Expected 0 releases when no commits, got %d", len(releases))
 	}
 }
+
+func TestExportMarkdown_GroupsByScope(t *testing.T) {
+	rel := &Release{
+		Version: "v1.6.0",
+		Entries: []ChangeEntry{
+			{Subject: "add endpoint", Tag: "feature", Scope: "api", ShortHash: "a1"},
+			{Subject: "fix crash", Tag: "fix", ShortHash: "b2"},
+			{Subject: "new button", Tag: "feature", Scope: "ui", ShortHash: "c3"},
+			{Subject: "pagination", Tag: "feature", Scope: "api", ShortHash: "d4"},
+			{Subject: "plain feature", Tag: "feature", ShortHash: "e5"},
+		},
+	}
+
+	g := &Generator{}
+	output := g.ExportMarkdown([]*Release{rel})
+
+	expected := "- **feature:** plain feature (`e5`)\n" +
+		"- **feature(api):**\n" +
+		"  - add endpoint (`a1`)\n" +
+		"  - pagination (`d4`)\n" +
+		"- **feature(ui):**\n" +
+		"  - new button (`c3`)\n" +
+		"- **fix:** fix crash (`b2`)\n"
+	if !strings.Contains(output, expected) {
+		t.Errorf("Entries not grouped by type and scope. Output:\n%s", output)
+	}
+
+	summary := g.FormatSummary([]*Release{rel})
+	if !strings.Contains(summary, "  feature(api):\n") {
+		t.Errorf("Summary missing scope group. Output:\n%s", summary)
+	}
+}
+
+func TestGenerateRelease_Scope(t *testing.T) {
+	mock := git.NewMockRunner()
+	mock.Commits = []git.Commit{
+		{Hash: "h1", ShortHash: "h1", Message: "feat(api): add endpoint"},
+		{Hash: "h2", ShortHash: "h2", Message: "fix: plain fix"},
+	}
+	meta := &workspace.Meta{
+		Scopes: map[string]string{"h2": "core"},
+	}
+
+	rel, err := NewGenerator(mock, meta).GenerateRelease(context.Background(), "v1", "", "HEAD")
+	if err != nil {
+		t.Fatalf("GenerateRelease failed: %v", err)
+	}
+	if rel.Entries[0].Scope != "api" {
+		t.Errorf("Expected detected scope api, got %q", rel.Entries[0].Scope)
+	}
+	if rel.Entries[1].Scope != "core" {
+		t.Errorf("Expected recorded scope core, got %q", rel.Entries[1].Scope)
+	}
+}
+
+func TestGenerateRelease_TextOverrides(t *testing.T) {
+	mock := git.NewMockRunner()
+	mock.Commits = []git.Commit{
+		{Hash: "h1", ShortHash: "h1", Message: "fix(ui): fix stuff"},
+		{Hash: "h2", ShortHash: "h2", Message: "feat: add export"},
+	}
+	meta := &workspace.Meta{
+		Subjects:     map[string]string{"h1": "Fix the crash when saving an empty file (#12)"},
+		Descriptions: map[string]string{"h1": "Saving used to fail silently.\nIt now creates the file."},
+	}
+
+	gen := NewGenerator(mock, meta)
+	rel, err := gen.GenerateRelease(context.Background(), UnreleasedVersion, "", "HEAD")
+	if err != nil {
+		t.Fatalf("GenerateRelease failed: %v", err)
+	}
+	e := rel.Entries[0]
+	if e.Subject != "Fix the crash when saving an empty file (#12)" || e.Tag != "fix" || e.Scope != "ui" {
+		t.Errorf("Override should replace the subject but keep type and scope: %+v", e)
+	}
+	if len(e.Issues) != 1 || e.Issues[0].ID != "#12" {
+		t.Errorf("Issues should be read from the override, got %+v", e.Issues)
+	}
+	if rel.Entries[1].Subject != "feat: add export" || rel.Entries[1].Description != "" {
+		t.Errorf("Entries without override should be unchanged: %+v", rel.Entries[1])
+	}
+
+	md := gen.ExportMarkdown([]*Release{rel})
+	want := "  - Fix the crash when saving an empty file (#12) (`h1`)\n    Saving used to fail silently.\n    It now creates the file.\n"
+	if !strings.Contains(md, want) {
+		t.Errorf("Missing description in:\n%s", md)
+	}
+}
+
+func TestGenerateRelease_Dates(t *testing.T) {
+	older := time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC)
+	newer := time.Date(2024, 4, 2, 18, 30, 0, 0, time.FixedZone("-03", -3*60*60))
+	tagged := time.Date(2024, 4, 5, 9, 0, 0, 0, time.UTC)
+
+	mock := git.NewMockRunner()
+	mock.Commits = []git.Commit{
+		{Hash: "h1", ShortHash: "h1", Message: "feat: first", CommitterDate: older},
+		{Hash: "h2", ShortHash: "h2", Message: "fix: second", CommitterDate: newer},
+	}
+	mock.TagDates = map[string]time.Time{"v1.0.0": tagged}
+	gen := NewGenerator(mock, &workspace.Meta{})
+	ctx := context.Background()
+
+	rel, err := gen.GenerateRelease(ctx, "v1.0.0", "", "v1.0.0")
+	if err != nil {
+		t.Fatalf("GenerateRelease failed: %v", err)
+	}
+	if !rel.Date.Equal(tagged) {
+		t.Errorf("Release should be dated by its tag, got %v", rel.Date)
+	}
+	if !rel.Entries[1].Date.Equal(newer) || rel.Entries[1].Date.Location() != newer.Location() {
+		t.Errorf("Entry should keep its committer date and timezone, got %v", rel.Entries[1].Date)
+	}
+
+	unreleased, err := gen.GenerateRelease(ctx, UnreleasedVersion, "v1.0.0", "HEAD")
+	if err != nil {
+		t.Fatalf("GenerateRelease failed: %v", err)
+	}
+	if !unreleased.Date.Equal(newer) {
+		t.Errorf("Unreleased changes should be dated by the newest commit, got %v", unreleased.Date)
+	}
+
+	data, _ := gen.ExportJSON([]*Release{rel})
+	if !strings.Contains(string(data), `"date": "2024-04-02T18:30:00-03:00"`) {
+		t.Errorf("JSON should carry absolute RFC 3339 dates:\n%s", data)
+	}
+	if md := gen.ExportMarkdown([]*Release{rel}); !strings.Contains(md, "- **Date:** 2024-04-05") {
+		t.Errorf("Markdown should show the release date:\n%s", md)
+	}
+}
+
+func TestGenerateRelease_BreakingChanges(t *testing.T) {
+	mock := git.NewMockRunner()
+	mock.Commits = []git.Commit{
+		{Hash: "h1", ShortHash: "h1", Message: "feat: new config", Body: "feat: new config\n\nBREAKING CHANGE: the old keys are ignored"},
+		{Hash: "h2", ShortHash: "h2", Message: "refactor!: drop v1 api"},
+		{Hash: "h3", ShortHash: "h3", Message: "fix: rename flag"},
+		{Hash: "h4", ShortHash: "h4", Message: "fix: small fix"},
+	}
+	meta := &workspace.Meta{
+		Impacts:  map[string]string{"h3": "major"},
+		Breaking: map[string]string{"h3": "use --output instead of -o"},
+	}
+
+	gen := NewGenerator(mock, meta)
+	rel, err := gen.GenerateRelease(context.Background(), "v2.0.0", "", "HEAD")
+	if err != nil {
+		t.Fatalf("GenerateRelease failed: %v", err)
+	}
+
+	if len(rel.BreakingChanges) != 3 {
+		t.Fatalf("Expected 3 breaking changes, got %d", len(rel.BreakingChanges))
+	}
+	want := []string{"the old keys are ignored", "drop v1 api", "use --output instead of -o"}
+	for i, w := range want {
+		if rel.BreakingChanges[i].Description != w {
+			t.Errorf("Breaking change %d: expected %q, got %q", i, w, rel.BreakingChanges[i].Description)
+		}
+	}
+
+	md := gen.ExportMarkdown([]*Release{rel})
+	section := strings.Index(md, "### Breaking changes")
+	if section < 0 || section > strings.Index(md, "---") {
+		t.Errorf("Breaking changes section should come before the entries. Output:\n%s", md)
+	}
+	if !strings.Contains(md, "- **fix: rename flag** (`h3`)\n  use --output instead of -o\n") {
+		t.Errorf("Missing migration notes. Output:\n%s", md)
+	}
+
+	summary := gen.FormatSummary([]*Release{rel})
+	if !strings.Contains(summary, "Breaking changes:\n  ! feat: new config (h1)\n    the old keys are ignored\n") {
+		t.Errorf("Summary missing breaking changes. Output:\n%s", summary)
+	}
+
+	data, err := gen.ExportJSON([]*Release{rel})
+	if err != nil {
+		t.Fatalf("ExportJSON failed: %v", err)
+	}
+	if !strings.Contains(string(data), `"breaking_changes"`) {
+		t.Errorf("JSON missing breaking_changes. Output:\n%s", data)
+	}
+}
+
+func TestGenerateRelease_Reverts(t *testing.T) {
+	mock := git.NewMockRunner()
+	mock.Commits = []git.Commit{
+		{Hash: "aaa111", ShortHash: "aaa1", Message: "feat: add login"},
+		{Hash: "bbb222", ShortHash: "bbb2", Message: "fix: keep me"},
+		{Hash: "ccc333", ShortHash: "ccc3", Message: `Revert "feat: add login"`, Body: "Revert \"feat: add login\"\n\nThis reverts commit aaa111."},
+		{Hash: "ddd444", ShortHash: "ddd4", Message: `Revert "feat: old feature"`, Body: "Revert \"feat: old feature\"\n\nThis reverts commit 9999999abcdef."},
+		{Hash: "eee555", ShortHash: "eee5", Message: "feat!: experimental api"},
+		{Hash: "fff666", ShortHash: "fff6", Message: "revert: feat!: experimental api"},
+	}
+
+	// a subject edited for the changelog doesn't keep the revert from matching
+	gen := NewGenerator(mock, &workspace.Meta{Subjects: map[string]string{"eee555": "Experimental API, at last"}})
+	rel, err := gen.GenerateRelease(context.Background(), "v1.1.0", "", "HEAD")
+	if err != nil {
+		t.Fatalf("GenerateRelease failed: %v", err)
+	}
+
+	if len(rel.Entries) != 1 || rel.Entries[0].Hash != "bbb222" {
+		t.Errorf("Expected only the untouched fix to remain, got %+v", rel.Entries)
+	}
+	if len(rel.BreakingChanges) != 0 {
+		t.Errorf("Reverted breaking change should not be listed, got %+v", rel.BreakingChanges)
+	}
+	if len(rel.Reverted) != 1 || rel.Reverted[0].Reverts != "9999999abcdef" {
+		t.Fatalf("Expected the cross-release revert in Reverted, got %+v", rel.Reverted)
+	}
+
+	md := gen.ExportMarkdown([]*Release{rel})
+	if !strings.Contains(md, "### Reverted\n\n- Revert \"feat: old feature\" (`ddd4`), reverts `9999999`\n") {
+		t.Errorf("Missing Reverted section. Output:\n%s", md)
+	}
+	if strings.Contains(md, "add login") {
+		t.Errorf("Reverted feature should not be listed as shipped. Output:\n%s", md)
+	}
+}
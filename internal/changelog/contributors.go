@@ -0,0 +1,106 @@
+package changelog
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"tutugit/internal/git"
+)
+
+// Contributor -> a person credited in a release. Identities are merged by email, after
+// the aliases of the config are applied.
+type Contributor struct {
+	Name      string `json:"name"`
+	Email     string `json:"email"`
+	Commits   int    `json:"commits"`
+	FirstTime bool   `json:"first_time,omitempty"` // no commit in any earlier release
+}
+
+// identity -> the canonical name and email of an author. Aliases map an email to
+// "Name <email>" or to another email, like a .mailmap file; emails compare case-insensitively.
+func (g *Generator) identity(name, email string) (string, string) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if g.Config == nil {
+		return name, email
+	}
+	for alias, canonical := range g.Config.Changelog.Mailmap {
+		if !strings.EqualFold(alias, email) {
+			continue
+		}
+		canonical = strings.TrimSpace(canonical)
+		if open := strings.Index(canonical, "<"); open >= 0 && strings.HasSuffix(canonical, ">") {
+			if n := strings.TrimSpace(canonical[:open]); n != "" {
+				name = n
+			}
+			return name, strings.ToLower(strings.TrimSpace(canonical[open+1 : len(canonical)-1]))
+		}
+		return name, strings.ToLower(canonical)
+	}
+	return name, email
+}
+
+// contributors -> counts the commits of each author, merges excluded. The most recent name
+// of an author wins. Sorted by commits, then by name.
+func (g *Generator) contributors(commits []git.Commit) []Contributor {
+	byEmail := make(map[string]*Contributor)
+	var order []string
+	for _, c := range commits {
+		if len(c.Parents) > 1 {
+			continue
+		}
+		name, email := g.identity(c.Author, c.Email)
+		if email == "" {
+			email = strings.ToLower(name)
+		}
+		if email == "" {
+			continue
+		}
+		ct, ok := byEmail[email]
+		if !ok {
+			ct = &Contributor{Email: email}
+			byEmail[email] = ct
+			order = append(order, email)
+		}
+		ct.Name = name
+		ct.Commits++
+	}
+
+	list := make([]Contributor, 0, len(order))
+	for _, email := range order {
+		list = append(list, *byEmail[email])
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].Commits != list[j].Commits {
+			return list[i].Commits > list[j].Commits
+		}
+		return strings.ToLower(list[i].Name) < strings.ToLower(list[j].Name)
+	})
+	return list
+}
+
+// knownAuthors -> the canonical emails of everyone with a commit reachable from rev.
+func (g *Generator) knownAuthors(ctx context.Context, rev string) map[string]bool {
+	known := make(map[string]bool)
+	commits, err := g.Git.GetHistory(ctx, rev, nil)
+	if err != nil {
+		return known
+	}
+	for _, ct := range g.contributors(commits) {
+		known[ct.Email] = true
+	}
+	return known
+}
+
+// markFirstTimers -> flags the contributors seen for the first time, given releases from
+// oldest to newest and the authors known before the oldest one. known is updated.
+func markFirstTimers(releases []*Release, known map[string]bool) {
+	for _, rel := range releases {
+		for i := range rel.Contributors {
+			rel.Contributors[i].FirstTime = !known[rel.Contributors[i].Email]
+		}
+		for _, ct := range rel.Contributors {
+			known[ct.Email] = true
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package changelog
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"tutugit/internal/config"
+	"tutugit/internal/git"
+	"tutugit/internal/workspace"
+)
+
+func TestContributors(t *testing.T) {
+	gen := NewGenerator(git.NewMockRunner(), &workspace.Meta{})
+	gen.Config = &config.Config{Changelog: config.Changelog{Mailmap: map[string]string{
+		"jane@old-laptop.local": "Jane Doe <jane@example.com>",
+		"JD@example.com":        "jane@example.com",
+	}}}
+
+	got := gen.contributors([]git.Commit{
+		{Hash: "1", Author: "jane", Email: "jane@old-laptop.local"},
+		{Hash: "2", Author: "Bob", Email: "bob@example.com"},
+		{Hash: "3", Author: "J. Doe", Email: "jd@example.com"},
+		{Hash: "4", Author: "Jane Doe", Email: "Jane@Example.com"},
+		{Hash: "5", Author: "Bob", Email: "bob@example.com", Parents: []string{"2", "3"}}, // merges don't count
+		{Hash: "6", Author: "Ann", Email: "ann@example.com"},
+	})
+	want := []Contributor{
+		{Name: "Jane Doe", Email: "jane@example.com", Commits: 3},
+		{Name: "Ann", Email: "ann@example.com", Commits: 1},
+		{Name: "Bob", Email: "bob@example.com", Commits: 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("contributors() = %+v, want %+v", got, want)
+	}
+}
+
+func TestGenerateFull_FirstTimeContributors(t *testing.T) {
+	mock := git.NewMockRunner()
+	mock.Tags = []string{"v1.0.0", "v1.1.0"}
+	mock.TagCommits = map[string]string{"v1.0.0": "c1", "v1.1.0": "c3"}
+	mock.Commits = []git.Commit{
+		{Hash: "c5", ShortHash: "c5", Parents: []string{"c4"}, Author: "Carol", Email: "carol@example.com", Message: "feat: export"},
+		{Hash: "c4", ShortHash: "c4", Parents: []string{"c3"}, Author: "Bob", Email: "bob@example.com", Message: "fix: crash"},
+		{Hash: "c3", ShortHash: "c3", Parents: []string{"c2"}, Author: "Bob", Email: "bob@example.com", Message: "fix: typo"},
+		{Hash: "c2", ShortHash: "c2", Parents: []string{"c1"}, Author: "Alice", Email: "alice@example.com", Message: "feat: search"},
+		{Hash: "c1", ShortHash: "c1", Author: "Alice", Email: "alice@example.com", Message: "feat: init"},
+	}
+
+	gen := NewGenerator(mock, &workspace.Meta{})
+	releases, err := gen.GenerateFull(context.Background())
+	if err != nil {
+		t.Fatalf("GenerateFull failed: %v", err)
+	}
+	if len(releases) != 3 {
+		t.Fatalf("Expected 3 releases, got %d", len(releases))
+	}
+
+	firstTimers := func(rel *Release) []string {
+		var names []string
+		for _, ct := range rel.Contributors {
+			if ct.FirstTime {
+				names = append(names, ct.Name)
+			}
+		}
+		return names
+	}
+	if got := firstTimers(releases[2]); got != nil {
+		t.Errorf("Nobody is new in the first release, got %v", got)
+	}
+	if got := firstTimers(releases[1]); !reflect.DeepEqual(got, []string{"Bob"}) {
+		t.Errorf("v1.1.0 first-timers = %v, want Bob", got)
+	}
+	if got := firstTimers(releases[0]); !reflect.DeepEqual(got, []string{"Carol"}) {
+		t.Errorf("Unreleased first-timers = %v, want Carol", got)
+	}
+	if ct := releases[0].Contributors; len(ct) != 2 || ct[0].Name != "Bob" || ct[0].Commits != 1 {
+		t.Errorf("Unexpected unreleased contributors: %+v", ct)
+	}
+
+	md := gen.ExportMarkdown(releases[:1])
+	if !strings.Contains(md, "### Contributors\n\n- Bob (1 commit)\n- Carol (1 commit), first contribution\n") {
+		t.Errorf("Missing contributors in:\n%s", md)
+	}
+	summary := gen.FormatSummary(releases[:1])
+	if !strings.Contains(summary, "Contributors: Bob (1), Carol (1, first time)\n") {
+		t.Errorf("Missing contributors in:\n%s", summary)
+	}
+	if body := keepAChangelogBody(releases[0]); !strings.HasSuffix(body, "\n\nThanks to Bob, Carol (first contribution).") {
+		t.Errorf("Missing contributors in:\n%s", body)
+	}
+}
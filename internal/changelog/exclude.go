@@ -0,0 +1,127 @@
+package changelog
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	"tutugit/internal/config"
+	"tutugit/internal/git"
+	"tutugit/internal/workspace"
+)
+
+// commitTypeRegex captures the type of a conventional commit subject, e.g. "chore".
+var commitTypeRegex = regexp.MustCompile(`^([A-Za-z]+)(?:\([^)]*\))?!?:`)
+
+// exclusions -> the compiled exclusion rules of a project, plus the commits hidden by hand.
+type exclusions struct {
+	tags     map[string]bool
+	messages []*regexp.Regexp
+	authors  []*regexp.Regexp
+	paths    []string
+	hidden   map[string]bool
+}
+
+// newExclusions -> compiles the rules from the config. Loading the config rejects invalid
+// regular expressions, so the ones of a config built in code are skipped.
+func newExclusions(cfg *config.Config, meta *workspace.Meta) *exclusions {
+	ex := &exclusions{tags: make(map[string]bool)}
+	if meta != nil {
+		ex.hidden = meta.Hidden
+	}
+	if cfg == nil {
+		return ex
+	}
+
+	rules := cfg.Changelog.Exclude
+	for _, t := range rules.Tags {
+		ex.tags[strings.ToLower(t)] = true
+	}
+	ex.messages = compileAll(rules.Messages)
+	ex.authors = compileAll(rules.Authors)
+	ex.paths = rules.Paths
+	return ex
+}
+
+// compileAll -> compiles the valid patterns.
+func compileAll(patterns []string) []*regexp.Regexp {
+	var res []*regexp.Regexp
+	for _, p := range patterns {
+		if re, err := regexp.Compile(p); err == nil {
+			res = append(res, re)
+		}
+	}
+	return res
+}
+
+// excludes -> reports whether a commit, with its resolved tag, stays out of the changelog.
+// Tag rules match the semantic tag as well as the conventional type of the subject, so
+// "chore" works although chores have no semantic tag of their own. The commits setting the
+// version files of a release are always left out.
+func (ex *exclusions) excludes(c git.Commit, fullMessage, tag string, files []string) bool {
+	if ex.hidden[c.Hash] || ex.tags[strings.ToLower(tag)] || strings.HasPrefix(c.Message, ReleaseCommitPrefix) {
+		return true
+	}
+	if len(ex.tags) > 0 {
+		if m := commitTypeRegex.FindStringSubmatch(c.Message); m != nil && ex.tags[strings.ToLower(m[1])] {
+			return true
+		}
+	}
+	for _, re := range ex.messages {
+		if re.MatchString(fullMessage) {
+			return true
+		}
+	}
+	author := c.Author + " <" + c.Email + ">"
+	for _, re := range ex.authors {
+		if re.MatchString(author) {
+			return true
+		}
+	}
+	return len(ex.paths) > 0 && len(files) > 0 && allMatch(ex.paths, files)
+}
+
+// allMatch -> reports whether every file matches at least one of the globs.
+func allMatch(globs, files []string) bool {
+	for _, f := range files {
+		matched := false
+		for _, g := range globs {
+			if MatchPath(g, f) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// MatchPath -> matches a slash-separated path against a glob where "**" stands for any
+// number of directories, e.g. "docs/**" or "**/*.md". Other segments follow path.Match.
+func MatchPath(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			rest := pattern[1:]
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(rest, name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], name[0]); err != nil || !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
@@ -0,0 +1,83 @@
+package changelog
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"tutugit/internal/config"
+	"tutugit/internal/git"
+	"tutugit/internal/workspace"
+)
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"docs/**", "docs/guide/install.md", true},
+		{"docs/**", "docs/README.md", true},
+		{"docs/**", "cmd/docs.go", false},
+		{"**/*.md", "README.md", true},
+		{"**/*.md", "internal/a/b.md", true},
+		{"**/*.md", "internal/a/b.go", false},
+		{"*.lock", "go.lock", true},
+		{"*.lock", "vendor/go.lock", false},
+		{"internal/**/testdata/**", "internal/git/testdata/repo/x", true},
+	}
+	for _, tt := range tests {
+		if got := MatchPath(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("MatchPath(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateRelease_Exclusions(t *testing.T) {
+	mock := git.NewMockRunner()
+	mock.Commits = []git.Commit{
+		{Hash: "f1", ShortHash: "f1", Author: "Jane", Email: "jane@example.com", Message: "feat: search"},
+		{Hash: "c1", ShortHash: "c1", Author: "Jane", Email: "jane@example.com", Message: "chore: tidy up"},
+		{Hash: "b1", ShortHash: "b1", Author: "dependabot[bot]", Email: "49699333+dependabot[bot]@users.noreply.github.com", Message: "fix: bump yaml"},
+		{Hash: "w1", ShortHash: "w1", Author: "Jane", Email: "jane@example.com", Message: "fix: typo\n\n[skip changelog]",
+			Body: "fix: typo\n\n[skip changelog]"},
+		{Hash: "d1", ShortHash: "d1", Author: "Jane", Email: "jane@example.com", Message: "fix: wording in guide"},
+		{Hash: "d2", ShortHash: "d2", Author: "Jane", Email: "jane@example.com", Message: "fix: docs and code"},
+		{Hash: "h1", ShortHash: "h1", Author: "Jane", Email: "jane@example.com", Message: "feat: experiment"},
+	}
+	mock.CommitStats = map[string][]git.FileStat{
+		"d1": {{Path: "docs/guide.md", Additions: 1}},
+		"d2": {{Path: "docs/guide.md", Additions: 1}, {Path: "main.go", Deletions: 1}},
+	}
+
+	meta := &workspace.Meta{Hidden: map[string]bool{"h1": true}}
+	gen := NewGenerator(mock, meta)
+	gen.Config = &config.Config{Changelog: config.Changelog{Exclude: config.Exclude{
+		Tags:     []string{"Chore"},
+		Messages: []string{`\[skip changelog\]`, `(`}, // the invalid pattern is ignored
+		Authors:  []string{`\[bot\]`},
+		Paths:    []string{"docs/**"},
+	}}}
+
+	rel, err := gen.GenerateRelease(context.Background(), UnreleasedVersion, "", "HEAD")
+	if err != nil {
+		t.Fatalf("GenerateRelease failed: %v", err)
+	}
+	if got := hashes(rel.Entries); !reflect.DeepEqual(got, []string{"f1", "d2"}) {
+		t.Errorf("Entries = %v, want f1 and d2", got)
+	}
+}
+
+func TestGenerateRelease_ReleaseCommit(t *testing.T) {
+	mock := git.NewMockRunner()
+	mock.Commits = []git.Commit{
+		{Hash: "r1", ShortHash: "r1", Message: ReleaseCommitPrefix + "v1.4.0"},
+		{Hash: "f1", ShortHash: "f1", Message: "feat: search"},
+	}
+	rel, err := NewGenerator(mock, &workspace.Meta{}).GenerateRelease(context.Background(), UnreleasedVersion, "", "HEAD")
+	if err != nil {
+		t.Fatalf("GenerateRelease failed: %v", err)
+	}
+	if got := hashes(rel.Entries); !reflect.DeepEqual(got, []string{"f1"}) {
+		t.Errorf("Entries = %v, want the release commit left out", got)
+	}
+}
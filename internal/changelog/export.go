@@ -0,0 +1,45 @@
+package changelog
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Export formats, as picked in the summary view or on the command line.
+const (
+	FormatMarkdown = "markdown"
+	FormatJSON     = "json"
+	FormatHTML     = "html"
+	FormatAtom     = "atom"
+)
+
+// ExportFormats -> the export formats, in the order the summary view cycles through them.
+var ExportFormats = []string{FormatMarkdown, FormatJSON, FormatHTML, FormatAtom}
+
+// exportFiles -> the default file name of each format, in .tutugit/.
+var exportFiles = map[string]string{
+	FormatMarkdown: MarkdownTemplate,
+	FormatJSON:     "release.json",
+	FormatHTML:     HTMLTemplate,
+	FormatAtom:     "releases.atom",
+}
+
+// ExportFile -> the default file name of an export format, "" for unknown formats.
+func ExportFile(format string) string {
+	return exportFiles[strings.ToLower(format)]
+}
+
+// Export -> renders releases in one of the export formats.
+func (g *Generator) Export(releases []*Release, format string) ([]byte, error) {
+	switch strings.ToLower(format) {
+	case FormatMarkdown, "md":
+		return []byte(g.ExportMarkdown(releases)), nil
+	case FormatJSON:
+		return g.ExportJSON(releases)
+	case FormatHTML:
+		return []byte(g.ExportHTML(releases)), nil
+	case FormatAtom:
+		return g.ExportAtom(releases)
+	}
+	return nil, fmt.Errorf("unknown export format %q (want %s)", format, strings.Join(ExportFormats, ", "))
+}
@@ -0,0 +1,201 @@
+package changelog
+
+import (
+	"context"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"tutugit/internal/config"
+	"tutugit/internal/git"
+	"tutugit/internal/workspace"
+)
+
+func exportReleases() []*Release {
+	return []*Release{
+		{
+			Version: UnreleasedVersion,
+			Entries: []ChangeEntry{{ShortHash: "ddd4444", Subject: "feat: wip", Tag: "feature", Impact: "minor"}},
+		},
+		{
+			Version:    "v1.1.0",
+			Date:       time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC),
+			Previous:   "v1.0.0",
+			CompareURL: "https://github.com/o/r/compare/v1.0.0...v1.1.0",
+			Entries: []ChangeEntry{
+				{ShortHash: "aaa1111", Subject: "feat: render <b>bold</b> names (#12)", Tag: "feature", Impact: "minor",
+					Issues: []IssueRef{{ID: "#12", URL: "https://github.com/o/r/issues/12"}}, URL: "https://github.com/o/r/commit/aaa1111"},
+			},
+			Contributors: []Contributor{{Name: "Ann", Email: "ann@x.io", Commits: 1}},
+		},
+		{
+			Version: "v1.0.0",
+			Date:    time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC),
+			Entries: []ChangeEntry{{ShortHash: "bbb2222", Subject: "fix: crash", Tag: "fix", Impact: "patch"}},
+		},
+	}
+}
+
+func TestHTMLIssues(t *testing.T) {
+	refs := []IssueRef{
+		{ID: "#1", URL: "https://x/issues/1"},
+		{ID: "#12", URL: "https://x/issues/12"},
+		{ID: "#7", Action: "closes"},
+	}
+	got := string(htmlIssues("fix <tag> & #12, see #1", refs))
+	want := `fix &lt;tag&gt; &amp; <a href="https://x/issues/12">#12</a>, see <a href="https://x/issues/1">#1</a> (closes #7)`
+	if got != want {
+		t.Errorf("htmlIssues:\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestExportHTML(t *testing.T) {
+	g := &Generator{Config: &config.Config{Project: config.Project{Name: "Acme"}}}
+	out := g.ExportHTML(exportReleases())
+
+	for _, want := range []string{
+		"<!DOCTYPE html>",
+		"<title>Acme — Release Notes</title>",
+		`<a href="#v1-1-0">v1.1.0</a>`,
+		`<section id="v1-1-0">`,
+		`<section id="unreleased">`,
+		`render &lt;b&gt;bold&lt;/b&gt; names (<a href="https://github.com/o/r/issues/12">#12</a>)`,
+		`<a class="hash" href="https://github.com/o/r/commit/aaa1111"><code>aaa1111</code></a>`,
+		`<a href="https://github.com/o/r/compare/v1.0.0...v1.1.0">v1.0.0...v1.1.0</a>`,
+		"<li>Ann (1 commit)</li>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Missing %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "<b>bold</b>") {
+		t.Errorf("Commit subjects must be escaped:\n%s", out)
+	}
+}
+
+func TestExportAtom(t *testing.T) {
+	g := &Generator{Config: &config.Config{Project: config.Project{Name: "Acme"}}}
+	data, err := g.ExportAtom(exportReleases())
+	if err != nil {
+		t.Fatalf("ExportAtom failed: %v", err)
+	}
+
+	var feed struct {
+		ID      string `xml:"id"`
+		Title   string `xml:"title"`
+		Updated string `xml:"updated"`
+		Entries []struct {
+			ID      string `xml:"id"`
+			Title   string `xml:"title"`
+			Updated string `xml:"updated"`
+			Link    struct {
+				Href string `xml:"href,attr"`
+			} `xml:"link"`
+			Content string `xml:"content"`
+		} `xml:"entry"`
+	}
+	if err := xml.Unmarshal(data, &feed); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, data)
+	}
+
+	if feed.ID != "urn:tutugit:acme" || feed.Title != "Acme" || feed.Updated != "2024-05-02T10:00:00Z" {
+		t.Errorf("Unexpected feed header: %+v", feed)
+	}
+	if len(feed.Entries) != 2 {
+		t.Fatalf("Expected one entry per tagged release, got %d", len(feed.Entries))
+	}
+	e := feed.Entries[0]
+	if e.ID != "urn:tutugit:acme#v1-1-0" || e.Title != "v1.1.0" || e.Updated != "2024-05-02T10:00:00Z" {
+		t.Errorf("Unexpected entry: %+v", e)
+	}
+	if e.Link.Href != "https://github.com/o/r/compare/v1.0.0...v1.1.0" {
+		t.Errorf("Expected the compare link, got %q", e.Link.Href)
+	}
+	if !strings.Contains(e.Content, "render &lt;b&gt;bold&lt;/b&gt; names") || strings.Contains(e.Content, "<!DOCTYPE") {
+		t.Errorf("Expected the release as an HTML fragment, got:\n%s", e.Content)
+	}
+}
+
+func TestExportAtomWith_OverrideAndUndated(t *testing.T) {
+	dir := t.TempDir()
+	override := `{{define "release"}}<p>custom {{.Version}}</p>{{end}}`
+	if err := os.WriteFile(filepath.Join(dir, "release.html.tmpl"), []byte(override), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	templates, err := LoadTemplates(dir)
+	if err != nil {
+		t.Fatalf("LoadTemplates failed: %v", err)
+	}
+
+	rels := []*Release{{
+		Version: "v1.2.0",
+		Entries: []ChangeEntry{
+			{ShortHash: "ccc3333", Subject: "fix: a", Date: time.Date(2024, 6, 3, 8, 0, 0, 0, time.UTC)},
+			{ShortHash: "ddd4444", Subject: "fix: b", Date: time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC)},
+		},
+	}}
+	data, err := (&Generator{}).ExportAtomWith(templates[HTMLTemplate], rels)
+	if err != nil {
+		t.Fatalf("ExportAtomWith failed: %v", err)
+	}
+	out := string(data)
+	if !strings.Contains(out, "custom v1.2.0") {
+		t.Errorf("Expected the entry rendered with the override, got:\n%s", out)
+	}
+	if strings.Contains(out, "0001-01-01") || strings.Count(out, "<updated>2024-06-03T08:00:00Z</updated>") != 2 {
+		t.Errorf("Expected the undated release to take its newest commit date, got:\n%s", out)
+	}
+}
+
+func TestExport_Formats(t *testing.T) {
+	g := &Generator{}
+	for _, format := range ExportFormats {
+		if _, err := g.Export(exportReleases(), format); err != nil {
+			t.Errorf("Export(%s) failed: %v", format, err)
+		}
+		if ExportFile(format) == "" {
+			t.Errorf("No default file for %s", format)
+		}
+	}
+	if _, err := g.Export(nil, "pdf"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
+
+func TestExport_IntroAndHighlights(t *testing.T) {
+	rels := exportReleases()[1:2]
+	rels[0].Intro = "Names are <b>bold</b> now."
+	rels[0].Highlights = []string{"Bold names", "Faster & smaller"}
+	g := NewGenerator(git.NewMockRunner(), &workspace.Meta{})
+
+	outputs := map[string]string{
+		"summary":   g.FormatSummary(rels),
+		"changelog": g.UpdateKeepAChangelog(context.Background(), "", rels[0]),
+	}
+	for _, format := range ExportFormats {
+		data, err := g.Export(rels, format)
+		if err != nil {
+			t.Fatalf("%s export failed: %v", format, err)
+		}
+		outputs[format] = string(data)
+	}
+
+	for name, out := range outputs {
+		intro, highlight, entry := "Names are <b>bold</b> now.", "Faster & smaller", "aaa1111"
+		switch name {
+		case FormatHTML:
+			intro, highlight = "Names are &lt;b&gt;bold&lt;/b&gt; now.", "Faster &amp; smaller"
+		case FormatAtom:
+			intro, highlight = "Names are &amp;lt;b&amp;gt;bold&amp;lt;/b&amp;gt; now.", "Faster &amp;amp; smaller"
+		case FormatJSON:
+			intro, highlight, entry = `"intro": "Names are \u003cb\u003ebold\u003c/b\u003e now."`, `"Faster \u0026 smaller"`, `"entries"`
+		}
+		i, h, e := strings.Index(out, intro), strings.Index(out, highlight), strings.Index(out, entry)
+		if i < 0 || h < 0 || e < 0 || i > h || h > e {
+			t.Errorf("%s: expected the intro, then the highlights above the entries (%d, %d, %d):\n%s", name, i, h, e, out)
+		}
+	}
+}
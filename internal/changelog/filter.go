@@ -0,0 +1,176 @@
+package changelog
+
+import (
+	"sort"
+	"strings"
+)
+
+// Filter -> narrows releases down to the entries a reader cares about. Empty fields match
+// everything; the query matches subjects, descriptions, scopes, authors and hashes.
+type Filter struct {
+	Tag       string // semantic tag, "other" for untagged entries
+	Workspace string
+	Impact    string
+	Author    string
+	Query     string
+}
+
+// IsEmpty -> reports whether the filter keeps every entry.
+func (f Filter) IsEmpty() bool {
+	return f == Filter{}
+}
+
+// Match -> reports whether an entry passes the filter. A merge passes when one of the
+// commits it brought in does.
+func (f Filter) Match(e ChangeEntry) bool {
+	if f.matchOwn(e) {
+		return true
+	}
+	for _, c := range e.Commits {
+		if f.matchOwn(c) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f Filter) matchOwn(e ChangeEntry) bool {
+	if f.Tag != "" && displayTag(e.Tag) != f.Tag {
+		return false
+	}
+	if f.Workspace != "" && e.Workspace != f.Workspace {
+		return false
+	}
+	if f.Impact != "" && e.Impact != f.Impact {
+		return false
+	}
+	if f.Author != "" && !strings.EqualFold(e.Author, f.Author) {
+		return false
+	}
+	if q := strings.ToLower(strings.TrimSpace(f.Query)); q != "" {
+		text := strings.ToLower(strings.Join([]string{e.Subject, e.Description, e.Scope, e.Author, e.Hash, e.PullRequest}, "\n"))
+		if !strings.Contains(text, q) {
+			return false
+		}
+	}
+	return true
+}
+
+// Apply -> the releases with only the entries that pass the filter. Releases left without
+// entries are dropped. Breaking changes, reverts, workspaces and contributors follow the
+// kept entries; sizes are dropped, as they can't be told for part of a release.
+func (f Filter) Apply(releases []*Release) []*Release {
+	if f.IsEmpty() {
+		return releases
+	}
+
+	var res []*Release
+	for _, rel := range releases {
+		if out := keepEntries(rel, f.Match); out != nil {
+			res = append(res, out)
+		}
+	}
+	return res
+}
+
+// keepEntries -> a copy of the release with only the entries keep accepts, or nil when
+// none is left. Breaking changes, reverts, workspaces and contributors follow the kept
+// entries; sizes are dropped.
+func keepEntries(rel *Release, keep func(ChangeEntry) bool) *Release {
+	out := *rel
+	out.Entries, out.Reverted, out.BreakingChanges, out.Workspaces, out.Contributors, out.Stats = nil, nil, nil, nil, nil, nil
+
+	kept := make(map[string]bool)
+	commits := make(map[string]int) // contributor email -> kept commits
+	for _, e := range rel.Entries {
+		if !keep(e) {
+			continue
+		}
+		out.Entries = append(out.Entries, e)
+		kept[e.Hash] = true
+		if len(e.Commits) == 0 {
+			commits[contributorKey(e)]++
+		}
+		for _, c := range e.Commits {
+			commits[contributorKey(c)]++
+		}
+	}
+	for _, e := range rel.Reverted {
+		if keep(e) {
+			out.Reverted = append(out.Reverted, e)
+		}
+	}
+	if len(out.Entries) == 0 && len(out.Reverted) == 0 {
+		return nil
+	}
+
+	for _, bc := range rel.BreakingChanges {
+		if kept[bc.Hash] {
+			out.BreakingChanges = append(out.BreakingChanges, bc)
+		}
+	}
+	for _, ws := range rel.Workspaces {
+		if workspaceKept(ws.Name, out.Entries) {
+			out.Workspaces = append(out.Workspaces, WorkspaceInfo{Name: ws.Name, Description: ws.Description})
+		}
+	}
+	for _, ct := range rel.Contributors {
+		if n := commits[ct.Email]; n > 0 {
+			ct.Commits = n
+			out.Contributors = append(out.Contributors, ct)
+		}
+	}
+	return &out
+}
+
+// contributorKey -> the email a contributor of the entry is known by, like in contributors:
+// the canonical email, else the lowercased name.
+func contributorKey(e ChangeEntry) string {
+	if e.Email != "" {
+		return e.Email
+	}
+	return strings.ToLower(e.Author)
+}
+
+func workspaceKept(name string, entries []ChangeEntry) bool {
+	for _, e := range entries {
+		if e.Workspace == name {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterValues -> the values found in releases for each filter field, sorted, to offer
+// as choices: tags, workspaces, impacts and authors.
+func FilterValues(releases []*Release) (tags, workspaces, impacts, authors []string) {
+	seen := map[string]map[string]bool{"workspace": {}, "impact": {}, "author": {}}
+	var all []ChangeEntry
+	add := func(kind, value string, list *[]string) {
+		if value != "" && !seen[kind][value] {
+			seen[kind][value] = true
+			*list = append(*list, value)
+		}
+	}
+	for _, rel := range releases {
+		all = append(all, rel.Entries...)
+		for _, e := range rel.Entries {
+			add("workspace", e.Workspace, &workspaces)
+			add("impact", e.Impact, &impacts)
+			add("author", e.Author, &authors)
+			for _, c := range e.Commits {
+				add("author", c.Author, &authors)
+			}
+		}
+	}
+
+	// tags and impacts in their usual order, the rest alphabetically
+	for _, tg := range groupByType(all) {
+		tags = append(tags, tg.Tag)
+	}
+	weight := map[string]int{"major": 0, "minor": 1, "patch": 2}
+	sort.SliceStable(impacts, func(i, j int) bool { return weight[impacts[i]] < weight[impacts[j]] })
+	sort.Strings(workspaces)
+	sort.Slice(authors, func(i, j int) bool { return strings.ToLower(authors[i]) < strings.ToLower(authors[j]) })
+	return tags, workspaces, impacts, authors
+}
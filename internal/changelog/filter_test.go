@@ -0,0 +1,120 @@
+package changelog
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"tutugit/internal/config"
+	"tutugit/internal/git"
+	"tutugit/internal/workspace"
+)
+
+func filterReleases() []*Release {
+	return []*Release{
+		{
+			Version: "v1.1.0",
+			Entries: []ChangeEntry{
+				{Hash: "a1", Subject: "feat: login form", Tag: "feature", Impact: "minor", Workspace: "Auth", Author: "Ann", Email: "ann@example.com"},
+				{Hash: "a2", Subject: "fix: session timeout", Tag: "fix", Impact: "patch", Workspace: "Auth", Author: "Bob", Email: "bob@example.com"},
+				{Hash: "m1", Subject: "Update dependencies", Impact: "patch", Author: "Ann", Email: "ann@example.com", PullRequest: "#12", Commits: []ChangeEntry{
+					{Hash: "b1", Subject: "chore: bump yaml", Impact: "patch", Author: "Carol", Email: "carol@example.com"},
+				}},
+			},
+			BreakingChanges: []BreakingChange{{Hash: "a1", Subject: "login is required"}},
+			Workspaces:      []WorkspaceInfo{{Name: "Auth", Description: "Sign-in", Stats: &DiffStats{Files: 2}}},
+			Contributors:    []Contributor{{Name: "Ann", Email: "ann@example.com", Commits: 2}, {Name: "Bob", Email: "bob@example.com", Commits: 1}, {Name: "Carol", Email: "carol@example.com", Commits: 1}},
+			Stats:           &DiffStats{Files: 3},
+		},
+		{
+			Version: "v1.0.0",
+			Entries: []ChangeEntry{{Hash: "c0", Subject: "fix: crash on start", Tag: "fix", Impact: "patch", Author: "Bob", Email: "bob@example.com"}},
+		},
+	}
+}
+
+func TestFilter_Apply(t *testing.T) {
+	releases := filterReleases()
+	if got := (Filter{}).Apply(releases); !reflect.DeepEqual(got, releases) {
+		t.Errorf("An empty filter must keep everything")
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   map[string][]string // version -> entry hashes
+	}{
+		{"tag", Filter{Tag: "fix"}, map[string][]string{"v1.1.0": {"a2"}, "v1.0.0": {"c0"}}},
+		{"untagged", Filter{Tag: "other"}, map[string][]string{"v1.1.0": {"m1"}}},
+		{"workspace", Filter{Workspace: "Auth"}, map[string][]string{"v1.1.0": {"a1", "a2"}}},
+		{"impact", Filter{Impact: "minor"}, map[string][]string{"v1.1.0": {"a1"}}},
+		{"author", Filter{Author: "bob"}, map[string][]string{"v1.1.0": {"a2"}, "v1.0.0": {"c0"}}},
+		{"author of a merged commit", Filter{Author: "Carol"}, map[string][]string{"v1.1.0": {"m1"}}},
+		{"query", Filter{Query: "CRASH"}, map[string][]string{"v1.0.0": {"c0"}}},
+		{"query by pull request", Filter{Query: "#12"}, map[string][]string{"v1.1.0": {"m1"}}},
+		{"combined", Filter{Tag: "fix", Author: "Bob", Query: "session"}, map[string][]string{"v1.1.0": {"a2"}}},
+		{"nothing", Filter{Workspace: "Billing"}, map[string][]string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make(map[string][]string)
+			for _, rel := range tt.filter.Apply(releases) {
+				got[rel.Version] = hashes(rel.Entries)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Apply() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// the rest of a release follows the kept entries
+	rel := Filter{Author: "Bob"}.Apply(releases)[0]
+	if len(rel.BreakingChanges) != 0 || rel.Stats != nil {
+		t.Errorf("Expected no breaking changes and no size, got %+v, %+v", rel.BreakingChanges, rel.Stats)
+	}
+	if !reflect.DeepEqual(rel.Workspaces, []WorkspaceInfo{{Name: "Auth", Description: "Sign-in"}}) {
+		t.Errorf("Unexpected workspaces: %+v", rel.Workspaces)
+	}
+	if !reflect.DeepEqual(rel.Contributors, []Contributor{{Name: "Bob", Email: "bob@example.com", Commits: 1}}) {
+		t.Errorf("Unexpected contributors: %+v", rel.Contributors)
+	}
+	if len(releases[0].Entries) != 3 || releases[0].Stats == nil {
+		t.Errorf("Apply must not change the original releases")
+	}
+}
+
+func TestFilterValues(t *testing.T) {
+	tags, workspaces, impacts, authors := FilterValues(filterReleases())
+	if !reflect.DeepEqual(tags, []string{"feature", "fix", "other"}) {
+		t.Errorf("tags = %v", tags)
+	}
+	if !reflect.DeepEqual(workspaces, []string{"Auth"}) {
+		t.Errorf("workspaces = %v", workspaces)
+	}
+	if !reflect.DeepEqual(impacts, []string{"minor", "patch"}) {
+		t.Errorf("impacts = %v", impacts)
+	}
+	if !reflect.DeepEqual(authors, []string{"Ann", "Bob", "Carol"}) {
+		t.Errorf("authors = %v", authors)
+	}
+}
+
+func TestFilter_Apply_MailmapAlias(t *testing.T) {
+	mock := git.NewMockRunner()
+	mock.Commits = []git.Commit{
+		{Hash: "c2", ShortHash: "c2", Message: "fix: crash", Author: "ann", Email: "ann@old.example", Parents: []string{"c1"}},
+		{Hash: "c1", ShortHash: "c1", Message: "feat: login", Author: "Ann Lee", Email: "ann@example.com"},
+	}
+	gen := NewGenerator(mock, &workspace.Meta{})
+	gen.Config = &config.Config{Changelog: config.Changelog{Mailmap: map[string]string{"ann@old.example": "Ann Lee <ann@example.com>"}}}
+	releases, err := gen.GenerateFull(context.Background())
+	if err != nil {
+		t.Fatalf("GenerateFull failed: %v", err)
+	}
+
+	rel := Filter{Tag: "fix"}.Apply(releases)[0]
+	want := []Contributor{{Name: "Ann Lee", Email: "ann@example.com", Commits: 1}}
+	if !reflect.DeepEqual(rel.Contributors, want) {
+		t.Errorf("Expected the aliased author credited, got %+v", rel.Contributors)
+	}
+}
@@ -0,0 +1,144 @@
+package changelog
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Supported forge kinds. Unknown hosts use GitHub-style URLs, which most forges understand.
+const (
+	ForgeGitHub    = "github"
+	ForgeGitLab    = "gitlab"
+	ForgeBitbucket = "bitbucket"
+	ForgeGitea     = "gitea"
+)
+
+// Remote -> a parsed git remote, used to build links back to the hosting forge.
+type Remote struct {
+	Host string // e.g. github.com, or git.example.com:8443 for an http(s) remote on another port
+	Path string // e.g. owner/repo, without ".git"
+	Kind string // one of the Forge* kinds, or "" when unknown
+}
+
+// ParseRemote -> parses ssh ("git@host:owner/repo.git", "ssh://git@host:2222/owner/repo")
+// and http(s) remote URLs. The port of an http(s) remote is kept for the links. The forge is recognized from well-known hosts; self-hosted
+// instances can be identified with ForgeKind. Returns nil if the URL can't be understood.
+func ParseRemote(remoteURL string) *Remote {
+	remoteURL = strings.TrimSpace(remoteURL)
+	if remoteURL == "" {
+		return nil
+	}
+
+	var scheme, host, path string
+	if i := strings.Index(remoteURL, "://"); i >= 0 {
+		scheme = strings.ToLower(remoteURL[:i])
+		rest := remoteURL[i+3:]
+		slash := strings.Index(rest, "/")
+		if slash < 0 {
+			return nil
+		}
+		host, path = rest[:slash], rest[slash+1:]
+	} else if colon := strings.Index(remoteURL, ":"); colon >= 0 {
+		// scp-like syntax: [user@]host:path
+		host, path = remoteURL[:colon], remoteURL[colon+1:]
+	} else {
+		return nil
+	}
+
+	// drop credentials, and the port of ssh remotes: the web pages are served elsewhere
+	if at := strings.LastIndex(host, "@"); at >= 0 {
+		host = host[at+1:]
+	}
+	if colon := strings.Index(host, ":"); colon >= 0 && scheme != "http" && scheme != "https" {
+		host = host[:colon]
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if host == "" || path == "" {
+		return nil
+	}
+
+	r := &Remote{Host: strings.ToLower(host), Path: path}
+	r.Kind = ForgeKind(r.Host)
+	return r
+}
+
+// ForgeKind -> guesses the forge from a host name. Returns "" for unknown hosts.
+func ForgeKind(host string) string {
+	switch {
+	case strings.Contains(host, "github"):
+		return ForgeGitHub
+	case strings.Contains(host, "gitlab"):
+		return ForgeGitLab
+	case strings.Contains(host, "bitbucket"):
+		return ForgeBitbucket
+	case strings.Contains(host, "gitea"), host == "codeberg.org":
+		return ForgeGitea
+	}
+	return ""
+}
+
+// BaseURL -> the web URL of the repository.
+func (r *Remote) BaseURL() string {
+	return "https://" + r.Host + "/" + r.Path
+}
+
+// IssueURL -> the link template for issue numbers, with {id} as placeholder.
+func (r *Remote) IssueURL() string {
+	if r.Kind == ForgeGitLab {
+		return r.BaseURL() + "/-/issues/{id}"
+	}
+	return r.BaseURL() + "/issues/{id}"
+}
+
+// CommitURL -> the web page of a commit.
+func (r *Remote) CommitURL(sha string) string {
+	switch r.Kind {
+	case ForgeGitLab:
+		return r.BaseURL() + "/-/commit/" + sha
+	case ForgeBitbucket:
+		return r.BaseURL() + "/commits/" + sha
+	}
+	return r.BaseURL() + "/commit/" + sha
+}
+
+// PullRequestURL -> the web page of a pull (or merge) request, by number.
+func (r *Remote) PullRequestURL(number string) string {
+	switch r.Kind {
+	case ForgeGitLab:
+		return r.BaseURL() + "/-/merge_requests/" + number
+	case ForgeBitbucket:
+		return r.BaseURL() + "/pull-requests/" + number
+	case ForgeGitea:
+		return r.BaseURL() + "/pulls/" + number
+	}
+	return r.BaseURL() + "/pull/" + number
+}
+
+// CompareURL -> the web page comparing two revisions (tags, branches or commits).
+func (r *Remote) CompareURL(base, head string) string {
+	switch r.Kind {
+	case ForgeGitLab:
+		return r.BaseURL() + "/-/compare/" + base + "..." + head
+	case ForgeBitbucket:
+		// Bitbucket lists the newer revision first, separated by a carriage return
+		return r.BaseURL() + "/branches/compare/" + url.PathEscape(head) + "%0D" + url.PathEscape(base)
+	}
+	return r.BaseURL() + "/compare/" + base + "..." + head
+}
+
+// mdHash -> renders a short hash as inline code, linked to its commit page when known.
+func mdHash(shortHash, link string) string {
+	if link == "" {
+		return "`" + shortHash + "`"
+	}
+	return "[`" + shortHash + "`](" + link + ")"
+}
+
+// compareHead -> the revision a release is compared at: its tag, or HEAD when unreleased.
+func compareHead(version string) string {
+	if version == UnreleasedVersion {
+		return "HEAD"
+	}
+	return version
+}
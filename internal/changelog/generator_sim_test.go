@@ -4,6 +4,7 @@ import (
 	"context"
 	"strings"
 	"testing"
+	"time"
 	"tutugit/internal/git"
 	"tutugit/internal/workspace"
 )
@@ -13,12 +14,13 @@ func TestGenerator_Simulation(t *testing.T) {
 	mock := git.NewMockRunner()
 
 	// create some commits
+	now := time.Now()
 	mock.Commits = []git.Commit{
-		{Hash: "hash1", ShortHash: "h1", Message: "feat: add user login", Author: "Carlos", Date: "1 hour ago"},
-		{Hash: "hash2", ShortHash: "h2", Message: "fix: crash on logout", Author: "Carlos", Date: "45 mins ago"},
-		{Hash: "hash3", ShortHash: "h3", Message: "refactor: optimize database", Author: "John", Date: "30 mins ago"},
-		{Hash: "hash4", ShortHash: "h4", Message: "experiment: test new api", Author: "Carlos", Date: "10 mins ago"},
-		{Hash: "hash5", ShortHash: "h5", Message: "chore: update docs", Author: "Carlos", Date: "5 mins ago"},
+		{Hash: "hash1", ShortHash: "h1", Message: "feat: add user login", Author: "Carlos", CommitterDate: now.Add(-60 * time.Minute)},
+		{Hash: "hash2", ShortHash: "h2", Message: "fix: crash on logout", Author: "Carlos", CommitterDate: now.Add(-45 * time.Minute)},
+		{Hash: "hash3", ShortHash: "h3", Message: "refactor: optimize database", Author: "John", CommitterDate: now.Add(-30 * time.Minute)},
+		{Hash: "hash4", ShortHash: "h4", Message: "experiment: test new api", Author: "Carlos", CommitterDate: now.Add(-10 * time.Minute)},
+		{Hash: "hash5", ShortHash: "h5", Message: "chore: update docs", Author: "Carlos", CommitterDate: now.Add(-5 * time.Minute)},
 	}
 
 	// setup Meta with Workspace grouping
@@ -0,0 +1,95 @@
+package changelog
+
+import "sort"
+
+// tagOrder -> the order in which semantic tags are listed in summaries, with their plural labels.
+var tagOrder = []struct{ tag, label string }{
+	{"feature", "features"},
+	{"fix", "fixes"},
+	{"refactor", "refactors"},
+	{"experiment", "experiments"},
+	{"other", "other"},
+}
+
+// ScopeGroup -> entries of a type section that share the same scope.
+type ScopeGroup struct {
+	Scope   string
+	Entries []ChangeEntry
+}
+
+// TypeGroup -> entries of a release that share the same semantic tag,
+// split by scope. Unscoped entries always come first.
+type TypeGroup struct {
+	Tag    string
+	Scopes []ScopeGroup
+}
+
+// displayTag -> normalizes an entry tag for display ("" and "none" become "other").
+func displayTag(tag string) string {
+	if tag == "" || tag == "none" {
+		return "other"
+	}
+	return tag
+}
+
+// groupByType -> splits entries into type sections (in tagOrder, unknown tags
+// alphabetically before "other") and groups each section by scope.
+// The original order of entries is preserved inside each scope group.
+func groupByType(entries []ChangeEntry) []TypeGroup {
+	byTag := make(map[string][]ChangeEntry)
+	for _, e := range entries {
+		tag := displayTag(e.Tag)
+		byTag[tag] = append(byTag[tag], e)
+	}
+
+	known := make(map[string]bool)
+	for _, o := range tagOrder {
+		known[o.tag] = true
+	}
+	var extra []string
+	for tag := range byTag {
+		if !known[tag] {
+			extra = append(extra, tag)
+		}
+	}
+	sort.Strings(extra)
+
+	var tags []string
+	for _, o := range tagOrder[:len(tagOrder)-1] {
+		tags = append(tags, o.tag)
+	}
+	tags = append(tags, extra...)
+	tags = append(tags, "other")
+
+	var groups []TypeGroup
+	for _, tag := range tags {
+		list, ok := byTag[tag]
+		if !ok {
+			continue
+		}
+		groups = append(groups, TypeGroup{Tag: tag, Scopes: groupByScope(list)})
+	}
+	return groups
+}
+
+// groupByScope -> groups entries by scope, unscoped first, then scopes alphabetically.
+func groupByScope(entries []ChangeEntry) []ScopeGroup {
+	byScope := make(map[string][]ChangeEntry)
+	var scopes []string
+	for _, e := range entries {
+		if _, ok := byScope[e.Scope]; !ok && e.Scope != "" {
+			scopes = append(scopes, e.Scope)
+		}
+		byScope[e.Scope] = append(byScope[e.Scope], e)
+	}
+	sort.Strings(scopes)
+
+	var groups []ScopeGroup
+	if list, ok := byScope[""]; ok {
+		groups = append(groups, ScopeGroup{Entries: list})
+	}
+	for _, scope := range scopes {
+		groups = append(groups, ScopeGroup{Scope: scope, Entries: byScope[scope]})
+	}
+	return groups
+}
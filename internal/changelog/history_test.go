@@ -0,0 +1,159 @@
+package changelog
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"tutugit/internal/git"
+	"tutugit/internal/workspace"
+)
+
+// mergeHistory -> a history with a merged side branch and a branch merged after a release:
+//
+//	c1 (v1.0.0) ── c2 ─────── m1 (v1.1.0) ── c3 ── m2   (HEAD)
+//	  ├── s1 ─────────────────┘                   │
+//	  └── f1 ─────────────────────────────────────┘
+func mergeHistory() *git.MockRunner {
+	mock := git.NewMockRunner()
+	mock.Tags = []string{"v1.1.0", "v1.0.0"}
+	mock.TagCommits = map[string]string{"v1.1.0": "m1", "v1.0.0": "c1"}
+	mock.Commits = []git.Commit{
+		{Hash: "m2", ShortHash: "m2", Message: "Merge branch 'f'", Parents: []string{"c3", "f1"}},
+		{Hash: "f1", ShortHash: "f1", Message: "feat: late feature", Parents: []string{"c1"}},
+		{Hash: "c3", ShortHash: "c3", Message: "fix: after release", Parents: []string{"m1"}},
+		{Hash: "m1", ShortHash: "m1", Message: "Merge branch 's'", Parents: []string{"c2", "s1"}},
+		{Hash: "s1", ShortHash: "s1", Message: "feat: side work", Parents: []string{"c1"}},
+		{Hash: "c2", ShortHash: "c2", Message: "fix: main work", Parents: []string{"c1"}},
+		{Hash: "c1", ShortHash: "c1", Message: "feat: initial"},
+	}
+	return mock
+}
+
+func hashes(entries []ChangeEntry) []string {
+	var out []string
+	for _, e := range entries {
+		out = append(out, e.Hash)
+	}
+	return out
+}
+
+func TestGenerateFull_SinglePass(t *testing.T) {
+	gen := NewGenerator(mergeHistory(), &workspace.Meta{})
+	releases, err := gen.GenerateFull(context.Background())
+	if err != nil {
+		t.Fatalf("GenerateFull failed: %v", err)
+	}
+	if len(releases) != 3 {
+		t.Fatalf("Expected 3 releases, got %d", len(releases))
+	}
+
+	want := map[string][]string{
+		UnreleasedVersion: {"c3", "f1", "m2"}, // f1 was branched early but merged after v1.1.0
+		"v1.1.0":          {"c2", "s1", "m1"},
+		"v1.0.0":          {"c1"},
+	}
+	for _, rel := range releases {
+		if got := hashes(rel.Entries); !reflect.DeepEqual(got, want[rel.Version]) {
+			t.Errorf("%s: entries %v, want %v", rel.Version, got, want[rel.Version])
+		}
+	}
+	if releases[1].Previous != "v1.0.0" || releases[2].Previous != "" {
+		t.Errorf("Unexpected previous releases: %q, %q", releases[1].Previous, releases[2].Previous)
+	}
+}
+
+func TestGenerator_WorkspaceIndex(t *testing.T) {
+	meta := &workspace.Meta{Workspaces: []workspace.Workspace{
+		{ID: "a", Name: "Alpha", Commits: []string{"c1", "c2"}},
+		{ID: "b", Name: "Beta", Commits: []string{"c2", "s1"}},
+	}}
+	gen := NewGenerator(git.NewMockRunner(), meta)
+	for hash, want := range map[string]string{"c1": "Alpha", "c2": "Alpha", "s1": "Beta", "zz": ""} {
+		if got := gen.workspaceOf(hash); got != want {
+			t.Errorf("workspaceOf(%s) = %q, want %q", hash, got, want)
+		}
+	}
+}
+
+// historySpy -> records the revisions excluded from each history walk.
+type historySpy struct {
+	*git.MockRunner
+	excluded [][]string
+}
+
+func (h *historySpy) GetHistory(ctx context.Context, head string, exclude []string) ([]git.Commit, error) {
+	h.excluded = append(h.excluded, exclude)
+	return h.MockRunner.GetHistory(ctx, head, exclude)
+}
+
+func TestGenerateFull_Cache(t *testing.T) {
+	ctx := context.Background()
+	spy := &historySpy{MockRunner: mergeHistory()}
+	// keep the mock history linear so that excluding v1.1.0 stops the walk there
+	spy.Commits = spy.Commits[2:]
+	meta := &workspace.Meta{Tags: map[string][]string{}}
+	cachePath := filepath.Join(t.TempDir(), "cache", "releases.json")
+
+	newGen := func() *Generator {
+		gen := NewGenerator(spy, meta)
+		gen.CachePath = cachePath
+		return gen
+	}
+
+	first, err := newGen().GenerateFull(ctx)
+	if err != nil {
+		t.Fatalf("GenerateFull failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(cachePath), ".gitignore")); err != nil {
+		t.Errorf("cache directory should ignore itself: %v", err)
+	}
+
+	second, err := newGen().GenerateFull(ctx)
+	if err != nil {
+		t.Fatalf("GenerateFull failed: %v", err)
+	}
+	if !reflect.DeepEqual(spy.excluded[1], []string{"v1.1.0"}) {
+		t.Errorf("second run should only walk history after v1.1.0, excluded %v", spy.excluded[1])
+	}
+	if len(first) != len(second) {
+		t.Fatalf("cached run returned %d releases, want %d", len(second), len(first))
+	}
+	for i := range first {
+		if first[i].Version != second[i].Version || !reflect.DeepEqual(hashes(first[i].Entries), hashes(second[i].Entries)) {
+			t.Errorf("release %d differs: %s %v vs %s %v", i, first[i].Version, hashes(first[i].Entries), second[i].Version, hashes(second[i].Entries))
+		}
+	}
+
+	// metadata of commits outside the cached releases doesn't touch the cache
+	meta.Tags["c3"] = []string{"feat"}
+	meta.ActiveWorkspace = "ws"
+	third, err := newGen().GenerateFull(ctx)
+	if err != nil {
+		t.Fatalf("GenerateFull failed: %v", err)
+	}
+	if !reflect.DeepEqual(spy.excluded[2], []string{"v1.1.0"}) {
+		t.Errorf("unreleased metadata change should keep the cache, excluded %v", spy.excluded[2])
+	}
+	if third[0].Entries[0].Tag != "feat" {
+		t.Errorf("unreleased entry should pick up its new tag, got %+v", third[0].Entries[0])
+	}
+
+	// retagging or editing metadata of a cached commit invalidates the cache
+	meta.Tags["c2"] = []string{"refactor"}
+	if _, err := newGen().GenerateFull(ctx); err != nil {
+		t.Fatalf("GenerateFull failed: %v", err)
+	}
+	if spy.excluded[3] != nil {
+		t.Errorf("metadata change should trigger a full walk, excluded %v", spy.excluded[3])
+	}
+	spy.TagCommits["v1.0.0"] = "c2"
+	if _, err := newGen().GenerateFull(ctx); err != nil {
+		t.Fatalf("GenerateFull failed: %v", err)
+	}
+	if spy.excluded[4] != nil {
+		t.Errorf("moved tag should trigger a full walk, excluded %v", spy.excluded[4])
+	}
+}
@@ -0,0 +1,82 @@
+package changelog
+
+import (
+	htmltemplate "html/template"
+	"regexp"
+	"strings"
+
+	"tutugit/internal/assets"
+)
+
+// HTMLTemplate -> the name of the built-in HTML page, which also renders Atom entries.
+const HTMLTemplate = "release.html"
+
+// anchorRegex matches the characters left out of release anchors.
+var anchorRegex = regexp.MustCompile(`[^a-z0-9]+`)
+
+// anchor -> the id of a release on the HTML page, e.g. "v1.2.0" -> "v1-2-0".
+func anchor(version string) string {
+	id := strings.Trim(anchorRegex.ReplaceAllString(strings.ToLower(version), "-"), "-")
+	if id == "" {
+		return "release"
+	}
+	return id
+}
+
+// htmlIssues -> linkIssues for HTML: the subject is escaped and issue references become links.
+func htmlIssues(subject string, refs []IssueRef) htmltemplate.HTML {
+	esc := htmltemplate.HTMLEscapeString
+	link := func(ref IssueRef) string {
+		if ref.URL == "" {
+			return esc(ref.ID)
+		}
+		return `<a href="` + esc(ref.URL) + `">` + esc(ref.ID) + `</a>`
+	}
+
+	var extra []string
+	linked := make(map[string]string)
+	for _, ref := range refs {
+		if refIndex(subject, ref.ID) >= 0 {
+			if ref.URL != "" {
+				linked[ref.ID] = link(ref)
+			}
+			continue
+		}
+		label := link(ref)
+		if ref.Action != "" {
+			label = esc(ref.Action) + " " + label
+		}
+		extra = append(extra, label)
+	}
+
+	// escape the text between the references, linking the first occurrence of each
+	var b strings.Builder
+	b.WriteString(linkRefs(subject, linked, esc))
+	if len(extra) > 0 {
+		b.WriteString(" (" + strings.Join(extra, ", ") + ")")
+	}
+	return htmltemplate.HTML(b.String())
+}
+
+// htmlTemplate -> parses the built-in HTML page. Unlike the other layouts it is an
+// html/template, so everything coming from commits is escaped.
+func htmlTemplate() (*htmltemplate.Template, error) {
+	data, err := assets.TemplatesFS.ReadFile("templates/" + HTMLTemplate + TemplateExt)
+	if err != nil {
+		return nil, err
+	}
+	return htmltemplate.New(HTMLTemplate).Funcs(TemplateFuncs()).Parse(string(data))
+}
+
+// ExportHTML -> produces a self-contained HTML page with an index of the releases and
+// an anchor per release.
+func (g *Generator) ExportHTML(releases []*Release) string {
+	tmpl, err := htmlTemplate()
+	if err == nil {
+		var b strings.Builder
+		if err = tmpl.Execute(&b, g.templateData(releases)); err == nil {
+			return b.String()
+		}
+	}
+	return "Error rendering " + HTMLTemplate + ": " + err.Error()
+}
@@ -0,0 +1,197 @@
+package changelog
+
+import (
+	"regexp"
+	"strings"
+
+	"tutugit/internal/config"
+)
+
+// IssueRef -> a reference to an issue or ticket found in a commit message.
+type IssueRef struct {
+	ID     string `json:"id"`               // reference as written, e.g. "#12" or "PROJ-451"
+	URL    string `json:"url,omitempty"`    // link to the issue, if a template is known
+	Action string `json:"action,omitempty"` // closing keyword, e.g. "fixes"
+}
+
+// issuePattern -> a compiled reference format.
+type issuePattern struct {
+	re      *regexp.Regexp
+	url     string
+	builtin bool // builtin patterns capture the reference in group 1 and the ID in group 2
+}
+
+// closingKeywordRegex matches the closing keyword right before a reference, e.g. "Fixes " or "closes: ".
+var closingKeywordRegex = regexp.MustCompile(`(?i)\b(close[sd]?|fix(?:e[sd])?|resolve[sd]?)\s*:?\s*$`)
+
+// issuePatterns -> compiles the built-in "#123"/"GH-123" formats and the configured ones.
+// Loading the config rejects invalid patterns, so the ones of a config built in code are skipped.
+func issuePatterns(cfg *config.Config, remote *Remote) []issuePattern {
+	issueURL := ""
+	if cfg != nil && cfg.Changelog.IssueURL != "" {
+		issueURL = cfg.Changelog.IssueURL
+	} else if remote != nil {
+		issueURL = remote.IssueURL()
+	}
+
+	patterns := []issuePattern{
+		{re: regexp.MustCompile(`(?:^|[\s(\[,])(#(\d+))\b`), url: issueURL, builtin: true},
+		{re: regexp.MustCompile(`\b(GH-(\d+))\b`), url: issueURL, builtin: true},
+	}
+
+	if cfg == nil {
+		return patterns
+	}
+	for _, p := range cfg.Changelog.IssuePatterns {
+		re, err := regexp.Compile(p.Pattern)
+		if err != nil {
+			continue
+		}
+		patterns = append(patterns, issuePattern{re: re, url: p.URL})
+	}
+	return patterns
+}
+
+// extractIssues -> finds issue references in the subject and the footer of a commit message.
+// Each reference is returned once, in order of appearance.
+func extractIssues(patterns []issuePattern, subject, fullMessage string) []IssueRef {
+	text := subject
+	if footer := messageFooter(fullMessage); footer != "" {
+		text += "\n" + footer
+	}
+
+	var refs []IssueRef
+	seen := make(map[string]bool)
+	for _, p := range patterns {
+		for _, loc := range p.re.FindAllStringSubmatchIndex(text, -1) {
+			// configured patterns use group 1 as ID, or the whole match
+			refStart, refEnd := loc[0], loc[1]
+			idStart, idEnd := loc[0], loc[1]
+			if p.builtin {
+				refStart, refEnd = loc[2], loc[3]
+				idStart, idEnd = loc[4], loc[5]
+			} else if len(loc) >= 4 && loc[2] >= 0 {
+				idStart, idEnd = loc[2], loc[3]
+			}
+
+			ref := IssueRef{ID: text[refStart:refEnd]}
+			if seen[ref.ID] {
+				continue
+			}
+			seen[ref.ID] = true
+
+			if p.url != "" {
+				ref.URL = strings.ReplaceAll(p.url, "{id}", text[idStart:idEnd])
+			}
+			if kw := closingKeywordRegex.FindStringSubmatch(text[:refStart]); kw != nil {
+				ref.Action = strings.ToLower(kw[1])
+			}
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+// messageFooter -> returns the last paragraph of a message body, where git trailers such as
+// "Fixes: #12" live. The subject alone never counts as a footer.
+func messageFooter(fullMessage string) string {
+	msg := strings.TrimSpace(fullMessage)
+	idx := strings.LastIndex(msg, "\n\n")
+	if idx < 0 {
+		return ""
+	}
+	return strings.TrimSpace(msg[idx+2:])
+}
+
+// refIndex -> the position of the first mention of a reference in s, -1 when there is none.
+// A match followed by a letter or digit is part of a longer reference, like "#1" in "#12".
+func refIndex(s, id string) int {
+	for from := 0; from <= len(s); {
+		i := strings.Index(s[from:], id)
+		if i < 0 {
+			return -1
+		}
+		i += from
+		if end := i + len(id); end == len(s) || !isWordByte(s[end]) {
+			return i
+		}
+		from = i + 1
+	}
+	return -1
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+// linkRefs -> renders a subject in a single left-to-right pass: the text goes through esc, and
+// the first mention of each reference in linked is replaced by its link. When two references
+// start at the same position, the longest wins.
+func linkRefs(subject string, linked map[string]string, esc func(string) string) string {
+	var b strings.Builder
+	rest := subject
+	for len(rest) > 0 {
+		pos, id := -1, ""
+		for ref := range linked {
+			if i := refIndex(rest, ref); i >= 0 && (pos < 0 || i < pos || (i == pos && len(ref) > len(id))) {
+				pos, id = i, ref
+			}
+		}
+		if pos < 0 {
+			b.WriteString(esc(rest))
+			break
+		}
+		b.WriteString(esc(rest[:pos]) + linked[id])
+		delete(linked, id)
+		rest = rest[pos+len(id):]
+	}
+	return b.String()
+}
+
+// linkIssues -> renders a subject in Markdown, turning the references it mentions into links.
+// References that only appear in the footer are appended after the subject.
+func linkIssues(subject string, refs []IssueRef) string {
+	var extra []string
+	linked := make(map[string]string)
+	for _, ref := range refs {
+		label := ref.ID
+		if ref.URL != "" {
+			label = "[" + ref.ID + "](" + ref.URL + ")"
+		}
+		if refIndex(subject, ref.ID) >= 0 {
+			if ref.URL != "" {
+				linked[ref.ID] = label
+			}
+			continue
+		}
+		if ref.Action != "" {
+			label = ref.Action + " " + label
+		}
+		extra = append(extra, label)
+	}
+	subject = linkRefs(subject, linked, func(s string) string { return s })
+	if len(extra) > 0 {
+		subject += " (" + strings.Join(extra, ", ") + ")"
+	}
+	return subject
+}
+
+// plainIssues -> renders a subject as plain text, appending the references that only
+// appear in the footer.
+func plainIssues(subject string, refs []IssueRef) string {
+	var extra []string
+	for _, ref := range refs {
+		if refIndex(subject, ref.ID) >= 0 {
+			continue
+		}
+		label := ref.ID
+		if ref.Action != "" {
+			label = ref.Action + " " + label
+		}
+		extra = append(extra, label)
+	}
+	if len(extra) > 0 {
+		subject += " (" + strings.Join(extra, ", ") + ")"
+	}
+	return subject
+}
@@ -0,0 +1,104 @@
+package changelog
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"tutugit/internal/config"
+	"tutugit/internal/git"
+	"tutugit/internal/workspace"
+)
+
+func TestExtractIssues(t *testing.T) {
+	cfg := &config.Config{
+		Changelog: config.Changelog{
+			IssuePatterns: []config.IssuePattern{
+				{Pattern: `PROJ-\d+`, URL: "https://jira.example.com/browse/{id}"},
+				{Pattern: `[unclosed`},
+			},
+		},
+	}
+	patterns := issuePatterns(cfg, ParseRemote("git@github.com:acme/app.git"))
+
+	refs := extractIssues(patterns,
+		"fix: handle empty carts (#12)",
+		"fix: handle empty carts (#12)\n\nLonger explanation mentioning #99.\n\nFixes #9\nRefs: GH-3, PROJ-451",
+	)
+
+	want := []IssueRef{
+		{ID: "#12", URL: "https://github.com/acme/app/issues/12"},
+		{ID: "#9", URL: "https://github.com/acme/app/issues/9", Action: "fixes"},
+		{ID: "GH-3", URL: "https://github.com/acme/app/issues/3"},
+		{ID: "PROJ-451", URL: "https://jira.example.com/browse/PROJ-451"},
+	}
+	if len(refs) != len(want) {
+		t.Fatalf("Expected %d refs, got %+v", len(want), refs)
+	}
+	for i := range want {
+		if refs[i] != want[i] {
+			t.Errorf("ref %d: expected %+v, got %+v", i, want[i], refs[i])
+		}
+	}
+}
+
+func TestExtractIssues_ConfiguredURL(t *testing.T) {
+	cfg := &config.Config{Changelog: config.Changelog{IssueURL: "https://tracker.local/t/{id}"}}
+	refs := extractIssues(issuePatterns(cfg, nil), "fix: crash #7", "fix: crash #7")
+	if len(refs) != 1 || refs[0].URL != "https://tracker.local/t/7" {
+		t.Errorf("Expected configured issue URL, got %+v", refs)
+	}
+
+	refs = extractIssues(issuePatterns(nil, nil), "fix: crash #7", "fix: crash #7")
+	if len(refs) != 1 || refs[0].URL != "" {
+		t.Errorf("Expected an unlinked ref without remote or config, got %+v", refs)
+	}
+}
+
+func TestExportMarkdown_IssueLinks(t *testing.T) {
+	mock := git.NewMockRunner()
+	mock.RemoteURL = "https://gitlab.com/acme/app.git"
+	mock.Commits = []git.Commit{
+		{Hash: "h1", ShortHash: "h1", Message: "feat: add export (#4)", Body: "feat: add export (#4)\n\nCloses #5"},
+	}
+
+	gen := NewGenerator(mock, &workspace.Meta{})
+	rel, err := gen.GenerateRelease(context.Background(), "v1", "", "HEAD")
+	if err != nil {
+		t.Fatalf("GenerateRelease failed: %v", err)
+	}
+
+	md := gen.ExportMarkdown([]*Release{rel})
+	want := "- **feature:** feat: add export ([#4](https://gitlab.com/acme/app/-/issues/4)) (closes [#5](https://gitlab.com/acme/app/-/issues/5))"
+	if !strings.Contains(md, want) {
+		t.Errorf("Missing issue links. Output:\n%s", md)
+	}
+
+	summary := gen.FormatSummary([]*Release{rel})
+	if !strings.Contains(summary, "add export (#4) (closes #5)") {
+		t.Errorf("Missing footer refs in summary. Output:\n%s", summary)
+	}
+}
+
+func TestLinkIssues_OverlappingIDs(t *testing.T) {
+	refs := []IssueRef{
+		{ID: "#1", URL: "https://x/issues/1"},
+		{ID: "#12", URL: "https://x/issues/12"},
+	}
+	got := linkIssues("fix #12 and #1", refs)
+	want := "fix [#12](https://x/issues/12) and [#1](https://x/issues/1)"
+	if got != want {
+		t.Errorf("linkIssues:\n got %s\nwant %s", got, want)
+	}
+
+	// #1 only appears in the footer, the #12 of the subject doesn't mention it
+	refs = []IssueRef{{ID: "#12", URL: "https://x/issues/12"}, {ID: "#1", URL: "https://x/issues/1", Action: "fixes"}}
+	got = linkIssues("fix #12", refs)
+	want = "fix [#12](https://x/issues/12) (fixes [#1](https://x/issues/1))"
+	if got != want {
+		t.Errorf("linkIssues with a footer ref:\n got %s\nwant %s", got, want)
+	}
+	if got := plainIssues("fix #12", refs); got != "fix #12 (fixes #1)" {
+		t.Errorf("plainIssues = %s", got)
+	}
+}
@@ -0,0 +1,503 @@
+package changelog
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// keepAChangelogPreamble -> the header of a freshly created CHANGELOG.md.
+const keepAChangelogPreamble = `# Changelog
+
+All notable changes to this project will be documented in this file.
+
+The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.1.0/),
+and this project adheres to [Semantic Versioning](https://semver.org/spec/v2.0.0.html).`
+
+// versionHeadingRegex matches release headings such as "## [1.2.0] - 2024-05-01" or "## [Unreleased]",
+// and not other level-2 headings written by hand.
+var versionHeadingRegex = regexp.MustCompile(`(?i)^##\s+(?:\[(v?\d[^\]\s]*|unreleased)\]|(unreleased))(?:\s|$)`)
+
+// subsectionRegex matches the "### Added" headings inside a release section.
+var subsectionRegex = regexp.MustCompile(`^###\s+(.+?)\s*$`)
+
+// generatedSubsections -> the headings of the subsections keepAChangelogBody writes, in order.
+var generatedSubsections = []string{"highlights", "added", "changed", "removed", "fixed"}
+
+// generatedEntryRegex matches the list items keepAChangelogBody writes for commits, which end
+// with the short hash, linked or not: "- add pagination ([`1a2b3c4`](https://...))".
+var generatedEntryRegex = regexp.MustCompile("\\((?:\\[`[0-9a-f]{7,40}`\\]\\([^)\\s]*\\)|`[0-9a-f]{7,40}`)\\)$")
+
+// linkRefRegex matches link reference definitions such as "[1.2.0]: https://...".
+var linkRefRegex = regexp.MustCompile(`^\[([^\]]+)\]:\s*(\S+)\s*$`)
+
+// conventionalPrefixRegex matches the type/scope header of a conventional commit subject.
+var conventionalPrefixRegex = regexp.MustCompile(`^[A-Za-z]+(\([^)]*\))?!?:\s*`)
+
+// KeepAChangelog -> a CHANGELOG.md in Keep a Changelog format, split into the parts tutugit
+// manages. Text that tutugit doesn't generate (the intro, hand-written notes) is kept verbatim.
+type KeepAChangelog struct {
+	Preamble string             // everything before the first release heading
+	Sections []ChangelogSection // release sections, in file order
+	Links    []ChangelogLink    // link reference footer, in file order
+}
+
+// ChangelogSection -> a "## [version]" section and its raw Markdown body.
+type ChangelogSection struct {
+	Version string
+	Heading string
+	Body    string
+}
+
+// ChangelogLink -> a "[label]: url" link reference definition.
+type ChangelogLink struct {
+	Label string
+	URL   string
+}
+
+// ParseKeepAChangelog -> splits an existing changelog into preamble, sections and links.
+// Only the link references at the end of the file make up the links; the ones elsewhere stay
+// in the text. An empty input yields the standard Keep a Changelog preamble.
+func ParseKeepAChangelog(text string) *KeepAChangelog {
+	kc := &KeepAChangelog{}
+	if strings.TrimSpace(text) == "" {
+		kc.Preamble = keepAChangelogPreamble
+		return kc
+	}
+
+	var preamble []string
+	var current *ChangelogSection
+	var body []string
+	flush := func() {
+		if current != nil {
+			current.Body = strings.Trim(strings.Join(body, "\n"), "\n")
+			kc.Sections = append(kc.Sections, *current)
+		}
+	}
+
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	// the trailing block of link references, blank lines aside
+	end := len(lines)
+	for i := len(lines) - 1; i >= 0; i-- {
+		if strings.TrimSpace(lines[i]) == "" {
+			continue
+		}
+		if !linkRefRegex.MatchString(lines[i]) {
+			break
+		}
+		end = i
+	}
+	for _, line := range lines[end:] {
+		if match := linkRefRegex.FindStringSubmatch(line); match != nil {
+			kc.Links = append(kc.Links, ChangelogLink{Label: match[1], URL: match[2]})
+		}
+	}
+
+	for _, line := range lines[:end] {
+		if match := versionHeadingRegex.FindStringSubmatch(line); match != nil {
+			flush()
+			version := match[1]
+			if version == "" {
+				version = match[2]
+			}
+			current = &ChangelogSection{Version: version, Heading: line}
+			body = nil
+			continue
+		}
+		if current == nil {
+			preamble = append(preamble, line)
+		} else {
+			body = append(body, line)
+		}
+	}
+	flush()
+
+	kc.Preamble = strings.Trim(strings.Join(preamble, "\n"), "\n")
+	return kc
+}
+
+// Section -> returns the section of a version, or nil.
+func (kc *KeepAChangelog) Section(version string) *ChangelogSection {
+	for i := range kc.Sections {
+		if strings.EqualFold(kc.Sections[i].Version, version) {
+			return &kc.Sections[i]
+		}
+	}
+	return nil
+}
+
+// Upsert -> replaces the section of a version, or inserts it as the newest release,
+// right after the "Unreleased" section. The "Unreleased" section is created if missing.
+// A replaced section keeps the text that tutugit didn't write (see mergeHandWritten).
+func (kc *KeepAChangelog) Upsert(section ChangelogSection) {
+	if kc.Section(UnreleasedVersion) == nil {
+		kc.Sections = append([]ChangelogSection{{
+			Version: UnreleasedVersion,
+			Heading: "## [" + UnreleasedVersion + "]",
+		}}, kc.Sections...)
+	}
+
+	if existing := kc.Section(section.Version); existing != nil {
+		section.Body = mergeHandWritten(section.Body, existing.Body)
+		*existing = section
+		return
+	}
+
+	pos := 0
+	for pos < len(kc.Sections) && strings.EqualFold(kc.Sections[pos].Version, UnreleasedVersion) {
+		pos++
+	}
+	kc.Sections = append(kc.Sections[:pos], append([]ChangelogSection{section}, kc.Sections[pos:]...)...)
+}
+
+// sectionBody -> the body of a release section, split at its "### " subsections.
+type sectionBody struct {
+	lead []string // the text before the first subsection
+	subs []bodySubsection
+}
+
+// bodySubsection -> a "### " heading and the lines under it.
+type bodySubsection struct {
+	heading string
+	name    string // the title in lower case
+	lines   []string
+}
+
+// splitSectionBody -> splits the body of a release section at its subsections.
+func splitSectionBody(body string) sectionBody {
+	var sb sectionBody
+	for _, line := range strings.Split(body, "\n") {
+		if match := subsectionRegex.FindStringSubmatch(line); match != nil {
+			sb.subs = append(sb.subs, bodySubsection{heading: line, name: strings.ToLower(match[1])})
+			continue
+		}
+		if len(sb.subs) == 0 {
+			sb.lead = append(sb.lead, line)
+		} else {
+			sub := &sb.subs[len(sb.subs)-1]
+			sub.lines = append(sub.lines, line)
+		}
+	}
+	return sb
+}
+
+// find -> the subsection with a name, or nil.
+func (sb sectionBody) find(name string) *bodySubsection {
+	for i := range sb.subs {
+		if sb.subs[i].name == name {
+			return &sb.subs[i]
+		}
+	}
+	return nil
+}
+
+// mergeHandWritten -> the generated body of a section with the hand-written text of the body
+// it replaces. The existing text before the first subsection is kept when there is any, and
+// the generated intro is only written into a section without one. In the subsections tutugit
+// writes, the commit entries, highlights and the thanks are regenerated while the other lines
+// stay after them. Other subsections, such as a "### Security" written by hand, follow.
+func mergeHandWritten(generated, existing string) string {
+	gen, old := splitSectionBody(generated), splitSectionBody(existing)
+
+	// the thanks close the generated subsections
+	var thanks string
+	if n := len(gen.subs); n > 0 {
+		last := &gen.subs[n-1]
+		lines := trimBlankLines(last.lines)
+		if len(lines) > 0 && strings.HasPrefix(lines[len(lines)-1], "Thanks to ") {
+			thanks = lines[len(lines)-1]
+			last.lines = lines[:len(lines)-1]
+		}
+	}
+
+	hand := make(map[string]string)
+	var others []string
+	for _, sub := range old.subs {
+		if !slices.Contains(generatedSubsections, sub.name) {
+			others = append(others, sub.heading)
+			others = append(others, sub.lines...)
+			continue
+		}
+		owned := make(map[string]bool)
+		if g := gen.find(sub.name); g != nil {
+			for _, line := range g.lines {
+				owned[line] = true
+			}
+		}
+		var kept []string
+		for _, block := range lineBlocks(sub.lines) {
+			first := block[0]
+			if isListItem(first) && (owned[first] || generatedEntryRegex.MatchString(first)) || strings.HasPrefix(first, "Thanks to ") {
+				continue
+			}
+			kept = append(kept, block...)
+		}
+		if kept = trimBlankLines(kept); len(kept) > 0 {
+			hand[sub.name] = strings.Join(kept, "\n")
+		}
+	}
+
+	var parts []string
+	lead := trimBlankLines(old.lead)
+	if len(lead) == 0 {
+		lead = trimBlankLines(gen.lead)
+	}
+	if len(lead) > 0 {
+		parts = append(parts, strings.Join(lead, "\n"))
+	}
+	for _, name := range generatedSubsections {
+		var heading, text string
+		if sub := gen.find(name); sub != nil {
+			heading, text = sub.heading, strings.Join(trimBlankLines(sub.lines), "\n")
+		}
+		if extra, ok := hand[name]; ok {
+			if heading == "" {
+				heading = old.find(name).heading
+			}
+			switch {
+			case text == "":
+				text = extra
+			case isListItem(extra):
+				text += "\n" + extra // hand-added items continue the list
+			default:
+				text += "\n\n" + extra
+			}
+		}
+		if heading != "" && text != "" {
+			parts = append(parts, heading+"\n\n"+text)
+		}
+	}
+	if thanks != "" {
+		parts = append(parts, thanks)
+	}
+	if others = trimBlankLines(others); len(others) > 0 {
+		parts = append(parts, strings.Join(others, "\n"))
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// lineBlocks -> groups lines into list items, each with its indented continuation lines
+// (descriptions and nested commits), and single other lines.
+func lineBlocks(lines []string) [][]string {
+	var blocks [][]string
+	for i := 0; i < len(lines); i++ {
+		block := []string{lines[i]}
+		if isListItem(lines[i]) {
+			for i+1 < len(lines) && continuesItem(lines[i+1:]) {
+				i++
+				block = append(block, lines[i])
+			}
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+// continuesItem -> reports whether the first of the lines still belongs to the list item
+// above: it is indented, or blank with an indented line after it.
+func continuesItem(lines []string) bool {
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			return strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")
+		}
+	}
+	return false
+}
+
+// isListItem -> reports whether a line starts a top-level Markdown list item.
+func isListItem(line string) bool {
+	return strings.HasPrefix(line, "- ") || strings.HasPrefix(line, "* ")
+}
+
+// trimBlankLines -> the lines without the blank ones at both ends.
+func trimBlankLines(lines []string) []string {
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// SetLink -> adds or updates a link reference definition.
+func (kc *KeepAChangelog) SetLink(label, url string) {
+	for i := range kc.Links {
+		if strings.EqualFold(kc.Links[i].Label, label) {
+			kc.Links[i].URL = url
+			return
+		}
+	}
+	kc.Links = append(kc.Links, ChangelogLink{Label: label, URL: url})
+}
+
+// String -> renders the changelog back to Markdown. Links follow the order of the sections,
+// with unknown labels kept at the end.
+func (kc *KeepAChangelog) String() string {
+	var b strings.Builder
+	if kc.Preamble != "" {
+		b.WriteString(kc.Preamble + "\n\n")
+	}
+
+	for _, sec := range kc.Sections {
+		b.WriteString(sec.Heading + "\n")
+		if sec.Body != "" {
+			b.WriteString("\n" + sec.Body + "\n")
+		}
+		b.WriteString("\n")
+	}
+
+	written := make(map[string]bool)
+	var links []string
+	for _, sec := range kc.Sections {
+		for _, l := range kc.Links {
+			if strings.EqualFold(l.Label, sec.Version) && !written[l.Label] {
+				links = append(links, fmt.Sprintf("[%s]: %s", l.Label, l.URL))
+				written[l.Label] = true
+			}
+		}
+	}
+	for _, l := range kc.Links {
+		if !written[l.Label] {
+			links = append(links, fmt.Sprintf("[%s]: %s", l.Label, l.URL))
+			written[l.Label] = true
+		}
+	}
+	if len(links) > 0 {
+		b.WriteString(strings.Join(links, "\n") + "\n")
+	}
+
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
+
+// UpdateKeepAChangelog -> writes a release into the text of an existing CHANGELOG.md (which
+// may be empty) and returns the new text. The release section is inserted or replaced (see
+// Upsert), while the "Unreleased" section and the other sections are kept as written. Link references for the release
+// and for "Unreleased" are maintained when the forge is known. The heading is dated with
+// the release date, or today for a release without one.
+func (g *Generator) UpdateKeepAChangelog(ctx context.Context, existing string, rel *Release) string {
+	kc := ParseKeepAChangelog(existing)
+	label := changelogLabel(rel.Version)
+	date := rel.Date
+	if date.IsZero() {
+		date = time.Now()
+	}
+
+	kc.Upsert(ChangelogSection{
+		Version: label,
+		Heading: fmt.Sprintf("## [%s] - %s", label, date.Format("2006-01-02")),
+		Body:    keepAChangelogBody(rel),
+	})
+
+	if remote := g.loadRemote(ctx); remote != nil {
+		if rel.CompareURL != "" {
+			kc.SetLink(label, rel.CompareURL)
+		} else {
+			kc.SetLink(label, remote.BaseURL()+"/releases/tag/"+rel.Version)
+		}
+		// only the newest release moves the Unreleased link
+		if newest := kc.newestRelease(); newest != nil && strings.EqualFold(newest.Version, label) {
+			kc.SetLink(UnreleasedVersion, remote.CompareURL(rel.Version, "HEAD"))
+		}
+	}
+
+	return kc.String()
+}
+
+// newestRelease -> the first section that isn't "Unreleased".
+func (kc *KeepAChangelog) newestRelease() *ChangelogSection {
+	for i := range kc.Sections {
+		if !strings.EqualFold(kc.Sections[i].Version, UnreleasedVersion) {
+			return &kc.Sections[i]
+		}
+	}
+	return nil
+}
+
+// changelogLabel -> the version as written in Keep a Changelog headings ("v1.2.0" -> "1.2.0").
+// The package of a monorepo tag is left out, as each package has its own changelog
+// ("api/v1.2.0" -> "1.2.0").
+func changelogLabel(version string) string {
+	if i := strings.LastIndex(version, "/"); i >= 0 {
+		version = version[i+1:]
+	}
+	if len(version) > 1 && (version[0] == 'v' || version[0] == 'V') && version[1] >= '0' && version[1] <= '9' {
+		return version[1:]
+	}
+	return version
+}
+
+// keepAChangelogCategory -> maps a semantic tag to a Keep a Changelog category.
+func keepAChangelogCategory(tag string) string {
+	switch tag {
+	case "feature":
+		return "Added"
+	case "fix":
+		return "Fixed"
+	}
+	return "Changed"
+}
+
+// keepAChangelogBody -> renders the entries of a release grouped by Keep a Changelog category,
+// after its intro and highlights.
+func keepAChangelogBody(rel *Release) string {
+	byCategory := make(map[string][]string)
+	for _, tg := range groupByType(rel.Entries) {
+		category := keepAChangelogCategory(tg.Tag)
+		for _, sg := range tg.Scopes {
+			for _, e := range sg.Entries {
+				line := "- "
+				if e.Breaking != "" {
+					line += "**BREAKING:** "
+				}
+				if sg.Scope != "" {
+					line += "**" + sg.Scope + ":** "
+				}
+				line += linkIssues(stripType(e.Subject), e.Issues)
+				if e.PullRequest != "" {
+					line += fmt.Sprintf(" (%s)", mdLink(e.PullRequest, e.PullRequestURL))
+				}
+				line += fmt.Sprintf(" (%s)", mdHash(e.ShortHash, e.URL))
+				if e.Description != "" {
+					line += "\n" + indent(2, e.Description)
+				}
+				for _, c := range e.Commits {
+					line += fmt.Sprintf("\n  - %s (%s)", linkIssues(stripType(c.Subject), c.Issues), mdHash(c.ShortHash, c.URL))
+				}
+				byCategory[category] = append(byCategory[category], line)
+			}
+		}
+	}
+	for _, e := range rel.Reverted {
+		line := fmt.Sprintf("- %s (%s)", e.Subject, mdHash(e.ShortHash, e.URL))
+		byCategory["Removed"] = append(byCategory["Removed"], line)
+	}
+
+	var parts []string
+	if rel.Intro != "" {
+		parts = append(parts, rel.Intro)
+	}
+	if len(rel.Highlights) > 0 {
+		parts = append(parts, "### Highlights\n\n- "+strings.Join(rel.Highlights, "\n- "))
+	}
+	for _, category := range []string{"Added", "Changed", "Removed", "Fixed"} {
+		if lines, ok := byCategory[category]; ok {
+			parts = append(parts, "### "+category+"\n\n"+strings.Join(lines, "\n"))
+		}
+	}
+	if len(rel.Contributors) > 0 {
+		var names []string
+		for _, ct := range rel.Contributors {
+			name := ct.Name
+			if ct.FirstTime {
+				name += " (first contribution)"
+			}
+			names = append(names, name)
+		}
+		parts = append(parts, "Thanks to "+strings.Join(names, ", ")+".")
+	}
+	return strings.Join(parts, "\n\n")
+}
@@ -0,0 +1,169 @@
+package changelog
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"tutugit/internal/git"
+	"tutugit/internal/workspace"
+)
+
+const existingChangelog = `# Changelog
+
+Hand-written intro that must survive.
+
+## [Unreleased]
+
+- Work in progress, written by hand.
+
+## [1.0.0] - 2024-01-10
+
+### Added
+
+- First release.
+
+[Unreleased]: https://github.com/acme/app/compare/v1.0.0...HEAD
+[1.0.0]: https://github.com/acme/app/releases/tag/v1.0.0
+`
+
+func TestParseKeepAChangelog(t *testing.T) {
+	kc := ParseKeepAChangelog(existingChangelog)
+
+	if !strings.Contains(kc.Preamble, "Hand-written intro") {
+		t.Errorf("Preamble not parsed: %q", kc.Preamble)
+	}
+	if len(kc.Sections) != 2 || kc.Sections[0].Version != "Unreleased" || kc.Sections[1].Version != "1.0.0" {
+		t.Fatalf("Unexpected sections: %+v", kc.Sections)
+	}
+	if len(kc.Links) != 2 {
+		t.Errorf("Expected 2 links, got %+v", kc.Links)
+	}
+	if kc.String() != existingChangelog {
+		t.Errorf("Round trip changed the file:\n%s", kc.String())
+	}
+}
+
+func TestUpdateKeepAChangelog(t *testing.T) {
+	mock := git.NewMockRunner()
+	mock.RemoteURL = "git@github.com:acme/app.git"
+	mock.Commits = []git.Commit{
+		{Hash: "aaa1111111", ShortHash: "aaa1111", Message: "feat(api): add export"},
+		{Hash: "bbb2222222", ShortHash: "bbb2222", Message: "fix: crash on empty input"},
+	}
+	// tagged late in the evening in São Paulo: the heading keeps the tag's own day
+	mock.TagDates = map[string]time.Time{
+		"v1.1.0": time.Date(2024, 2, 1, 22, 30, 0, 0, time.FixedZone("-03", -3*60*60)),
+	}
+
+	gen := NewGenerator(mock, &workspace.Meta{})
+	ctx := context.Background()
+	rel, err := gen.GenerateRelease(ctx, "v1.1.0", "v1.0.0", "v1.1.0")
+	if err != nil {
+		t.Fatalf("GenerateRelease failed: %v", err)
+	}
+
+	out := gen.UpdateKeepAChangelog(ctx, existingChangelog, rel)
+
+	for _, want := range []string{
+		"Hand-written intro that must survive.",
+		"## [Unreleased]\n\n- Work in progress, written by hand.\n\n## [1.1.0] - 2024-02-01\n\n### Added\n\n- **api:** add export",
+		"### Fixed\n\n- crash on empty input ([`bbb2222`](https://github.com/acme/app/commit/bbb2222222))",
+		"## [1.0.0] - 2024-01-10",
+		"[Unreleased]: https://github.com/acme/app/compare/v1.1.0...HEAD\n[1.1.0]: https://github.com/acme/app/compare/v1.0.0...v1.1.0\n[1.0.0]: https://github.com/acme/app/releases/tag/v1.0.0\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Missing %q in output:\n%s", want, out)
+		}
+	}
+
+	// writing the same release again replaces its section instead of duplicating it
+	again := gen.UpdateKeepAChangelog(ctx, out, rel)
+	if again != out {
+		t.Errorf("Writing the release again changed the file:\n%s", again)
+	}
+
+	// text edited and added by hand survives a rewrite
+	edited := strings.Replace(out, "### Added\n\n", "Export your data at last.\n\n### Added\n\n", 1)
+	edited = strings.Replace(edited, "commit/aaa1111111))\n", "commit/aaa1111111))\n- Dark mode, merged by hand\n\nSee the migration guide.\n", 1)
+	again = gen.UpdateKeepAChangelog(ctx, edited, rel)
+	if !strings.Contains(again, "## [1.1.0] - 2024-02-01\n\nExport your data at last.\n\n### Added\n\n- **api:** add export ([`aaa1111`](https://github.com/acme/app/commit/aaa1111111))\n- Dark mode, merged by hand\n\nSee the migration guide.\n\n### Fixed\n") {
+		t.Errorf("Hand-written text lost:\n%s", again)
+	}
+	if strings.Count(again, "add export") != 1 {
+		t.Errorf("Generated entry duplicated:\n%s", again)
+	}
+}
+
+func TestUpdateKeepAChangelog_NewFile(t *testing.T) {
+	gen := NewGenerator(git.NewMockRunner(), &workspace.Meta{})
+	rel := &Release{Version: "v0.1.0", Date: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC), Entries: []ChangeEntry{{Subject: "feat: first", Tag: "feature", ShortHash: "a1"}}}
+
+	out := gen.UpdateKeepAChangelog(context.Background(), "", rel)
+	if !strings.HasPrefix(out, "# Changelog\n") {
+		t.Errorf("Missing standard preamble:\n%s", out)
+	}
+	if !strings.Contains(out, "## [Unreleased]\n\n## [0.1.0] - 2024-01-01\n\n### Added\n\n- first (`a1`)\n") {
+		t.Errorf("Unexpected sections:\n%s", out)
+	}
+}
+
+func TestParseKeepAChangelog_HandWritten(t *testing.T) {
+	text := `# Changelog
+
+## How to read this file
+
+Versions follow [SemVer][semver].
+
+[semver]: https://semver.org
+
+## [Unreleased]
+
+## [1.0.0] - 2024-01-10
+
+### Added
+
+- First release.
+
+[1.0.0]: https://github.com/acme/app/releases/tag/v1.0.0
+`
+	kc := ParseKeepAChangelog(text)
+	if !strings.Contains(kc.Preamble, "## How to read this file") || !strings.Contains(kc.Preamble, "[semver]: https://semver.org") {
+		t.Errorf("Expected the hand-written heading and its link in the preamble, got %q", kc.Preamble)
+	}
+	if len(kc.Sections) != 2 || kc.Sections[0].Version != "Unreleased" || kc.Sections[1].Version != "1.0.0" {
+		t.Fatalf("Unexpected sections: %+v", kc.Sections)
+	}
+	if len(kc.Links) != 1 || kc.Links[0].Label != "1.0.0" {
+		t.Errorf("Expected only the trailing link, got %+v", kc.Links)
+	}
+	if kc.String() != text {
+		t.Errorf("Round trip changed the file:\n%s", kc.String())
+	}
+}
+
+func TestUpsert_KeepsHandWrittenSubsections(t *testing.T) {
+	kc := ParseKeepAChangelog(`## [Unreleased]
+
+## [1.1.0] - 2024-02-01
+
+Old intro.
+
+### Security
+
+- Rotate your API keys after upgrading.
+
+### Fixed
+
+- old fix (` + "`aaa1111`" + `)
+
+Thanks to Ann.
+`)
+	kc.Upsert(ChangelogSection{Version: "1.1.0", Heading: "## [1.1.0] - 2024-02-01", Body: "New intro.\n\n### Fixed\n\n- new fix (`bbb2222`)\n\nThanks to Bob."})
+
+	want := "Old intro.\n\n### Fixed\n\n- new fix (`bbb2222`)\n\nThanks to Bob.\n\n### Security\n\n- Rotate your API keys after upgrading."
+	if got := kc.Section("1.1.0").Body; got != want {
+		t.Errorf("Unexpected body:\n%s\nwant:\n%s", got, want)
+	}
+}
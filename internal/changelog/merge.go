@@ -0,0 +1,170 @@
+package changelog
+
+import (
+	"regexp"
+	"strings"
+
+	"tutugit/internal/git"
+)
+
+// Merge subjects written by git and by the usual forges.
+var (
+	// "Merge pull request #42 from acme/feature/login" (GitHub, Gitea)
+	pullRequestMergeRegex = regexp.MustCompile(`^Merge pull request (#\d+) from (\S+)`)
+	// "Merged in feature/login (pull request #42)" (Bitbucket)
+	bitbucketMergeRegex = regexp.MustCompile(`^Merged in (\S+) \(pull request (#\d+)\)`)
+	// "Merge branch 'feature/login' into 'main'" (GitLab, plain git)
+	branchMergeRegex = regexp.MustCompile(`^Merge (?:remote-tracking )?branch '([^']+)'`)
+	// "See merge request acme/app!42" (GitLab body)
+	mergeRequestRegex = regexp.MustCompile(`(?m)^See merge request \S*?(!\d+)\s*$`)
+	// trailers that are never the pull request title, e.g. "Approved-by: ..." but not "feat: ..."
+	mergeTrailerRegex = regexp.MustCompile(`^(?:[A-Z][a-z]*(?:-[a-z]+)+: |See merge request )`)
+)
+
+// mergeInfo -> what a merge commit message tells about the merged work.
+type mergeInfo struct {
+	branch      string // merged branch, without the owner of a fork
+	pullRequest string // reference as written by the forge, e.g. "#42" or "!42"
+	title       string // pull request title, when the forge records it in the body
+}
+
+// parseMerge -> reads the branch, pull request and title out of a merge commit message.
+func parseMerge(fullMessage string) mergeInfo {
+	var info mergeInfo
+	msg := strings.TrimSpace(fullMessage)
+	subject, body, _ := strings.Cut(msg, "\n")
+
+	if m := pullRequestMergeRegex.FindStringSubmatch(subject); m != nil {
+		info.pullRequest = m[1]
+		info.branch = m[2]
+		// "owner/branch" -> "branch"
+		if _, branch, ok := strings.Cut(m[2], "/"); ok {
+			info.branch = branch
+		}
+	} else if m := bitbucketMergeRegex.FindStringSubmatch(subject); m != nil {
+		info.branch, info.pullRequest = m[1], m[2]
+	} else if m := branchMergeRegex.FindStringSubmatch(subject); m != nil {
+		info.branch = m[1]
+		if mr := mergeRequestRegex.FindStringSubmatch(body); mr != nil {
+			info.pullRequest = mr[1]
+		}
+	}
+
+	// the title is the first line of the body that isn't a trailer
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if !mergeTrailerRegex.MatchString(line) {
+			info.title = line
+		}
+		break
+	}
+	return info
+}
+
+// firstParent -> reports whether releases list merges instead of the commits they bring in.
+func (g *Generator) firstParent() bool {
+	return g.Config != nil && g.Config.Changelog.FirstParent
+}
+
+// nestMerges -> keeps only the first-parent history of a release at the top level and moves
+// the commits brought in by each merge under it. Commits shared by several merges belong to
+// the oldest one. The commits of a merge that has no entry, because it was excluded or
+// hidden, stay at the top level. Entries are in the order of commits (oldest first).
+func nestMerges(entries []ChangeEntry, commits []git.Commit, hide bool) []ChangeEntry {
+	if len(commits) == 0 {
+		return entries
+	}
+	inRelease := make(map[string]git.Commit, len(commits))
+	isParent := make(map[string]bool)
+	for _, c := range commits {
+		inRelease[c.Hash] = c
+		for _, p := range c.Parents {
+			isParent[p] = true
+		}
+	}
+
+	// the head is the newest commit no other commit of the release descends from
+	head := commits[len(commits)-1]
+	for i := len(commits) - 1; i >= 0; i-- {
+		if !isParent[commits[i].Hash] {
+			head = commits[i]
+			break
+		}
+	}
+
+	var mainline []git.Commit
+	onMainline := make(map[string]bool)
+	for c, ok := head, true; ok; {
+		mainline = append(mainline, c)
+		onMainline[c.Hash] = true
+		if len(c.Parents) == 0 {
+			break
+		}
+		c, ok = inRelease[c.Parents[0]]
+	}
+
+	// oldest merges claim first
+	owner := make(map[string]string)
+	for i := len(mainline) - 1; i >= 0; i-- {
+		merge := mainline[i]
+		if len(merge.Parents) < 2 {
+			continue
+		}
+		queue := append([]string(nil), merge.Parents[1:]...)
+		for len(queue) > 0 {
+			hash := queue[0]
+			queue = queue[1:]
+			c, ok := inRelease[hash]
+			if !ok || onMainline[hash] || owner[hash] != "" {
+				continue
+			}
+			owner[hash] = merge.Hash
+			queue = append(queue, c.Parents...)
+		}
+	}
+
+	listed := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		listed[e.Hash] = true
+	}
+	nested := make(map[string][]ChangeEntry)
+	var top []ChangeEntry
+	for _, e := range entries {
+		if o := owner[e.Hash]; o != "" && listed[o] {
+			nested[o] = append(nested[o], e)
+			continue
+		}
+		top = append(top, e)
+	}
+
+	for i := range top {
+		children := nested[top[i].Hash]
+		if len(children) == 0 {
+			continue
+		}
+		// a merge without a conventional title takes the most significant type it brings in
+		if top[i].Tag == "" || top[i].Tag == "none" {
+			top[i].Tag = significantTag(children)
+		}
+		top[i].Impact = maxImpact(append([]ChangeEntry{top[i]}, children...))
+		if !hide {
+			top[i].Commits = children
+		}
+	}
+	return top
+}
+
+// significantTag -> the first of tagOrder found among entries, "none" if there is none.
+func significantTag(entries []ChangeEntry) string {
+	for _, o := range tagOrder {
+		for _, e := range entries {
+			if e.Tag == o.tag {
+				return o.tag
+			}
+		}
+	}
+	return "none"
+}
@@ -0,0 +1,144 @@
+package changelog
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"tutugit/internal/config"
+	"tutugit/internal/git"
+	"tutugit/internal/workspace"
+)
+
+func TestParseMerge(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    mergeInfo
+	}{
+		{
+			"github",
+			"Merge pull request #42 from acme/feature/login\n\nfeat: add login page",
+			mergeInfo{branch: "feature/login", pullRequest: "#42", title: "feat: add login page"},
+		},
+		{
+			"gitlab",
+			"Merge branch 'fix-crash' into 'main'\n\nfix: crash on save\n\nSee merge request acme/app!7",
+			mergeInfo{branch: "fix-crash", pullRequest: "!7", title: "fix: crash on save"},
+		},
+		{
+			"gitlab without description",
+			"Merge branch 'fix-crash' into 'main'\n\nSee merge request acme/app!7",
+			mergeInfo{branch: "fix-crash", pullRequest: "!7"},
+		},
+		{
+			"bitbucket",
+			"Merged in feature/x (pull request #3)\n\nAdd x\n\nApproved-by: Jane",
+			mergeInfo{branch: "feature/x", pullRequest: "#3", title: "Add x"},
+		},
+		{
+			"plain git",
+			"Merge branch 'topic'",
+			mergeInfo{branch: "topic"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseMerge(tt.message); got != tt.want {
+				t.Errorf("parseMerge() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// pullRequestHistory -> two pull requests merged into main, plus a direct commit:
+//
+//	c0 ── m1 ── d1 ── m2   (v1.0.0 at c0, HEAD at m2)
+//	 └ a1 ┘      └ b1 ─ b2 ┘
+func pullRequestHistory() *git.MockRunner {
+	mock := git.NewMockRunner()
+	mock.RemoteURL = "https://github.com/acme/app.git"
+	mock.Tags = []string{"v1.0.0"}
+	mock.TagCommits = map[string]string{"v1.0.0": "c0"}
+	mock.Commits = []git.Commit{
+		{Hash: "m2", ShortHash: "m2", Parents: []string{"d1", "b2"}, Message: "Merge pull request #12 from acme/chore-deps",
+			Body: "Merge pull request #12 from acme/chore-deps\n\nUpdate dependencies"},
+		{Hash: "b2", ShortHash: "b2", Parents: []string{"b1"}, Message: "fix: pin yaml version"},
+		{Hash: "b1", ShortHash: "b1", Parents: []string{"d1"}, Message: "chore: bump deps"},
+		{Hash: "d1", ShortHash: "d1", Parents: []string{"m1"}, Message: "docs: readme"},
+		{Hash: "m1", ShortHash: "m1", Parents: []string{"c0", "a1"}, Message: "Merge pull request #10 from acme/login",
+			Body: "Merge pull request #10 from acme/login\n\nfeat: add login"},
+		{Hash: "a1", ShortHash: "a1", Parents: []string{"c0"}, Message: "feat: login form"},
+		{Hash: "c0", ShortHash: "c0", Message: "chore: initial"},
+	}
+	return mock
+}
+
+func TestGenerateFull_FirstParent(t *testing.T) {
+	gen := NewGenerator(pullRequestHistory(), &workspace.Meta{})
+	gen.Config = &config.Config{Changelog: config.Changelog{FirstParent: true}}
+
+	releases, err := gen.GenerateFull(context.Background())
+	if err != nil {
+		t.Fatalf("GenerateFull failed: %v", err)
+	}
+	rel := releases[0]
+	if rel.Version != UnreleasedVersion {
+		t.Fatalf("Expected unreleased changes first, got %s", rel.Version)
+	}
+
+	if got := hashes(rel.Entries); !reflect.DeepEqual(got, []string{"m1", "d1", "m2"}) {
+		t.Fatalf("Top-level entries = %v, want the first-parent history", got)
+	}
+
+	login := rel.Entries[0]
+	if login.Subject != "feat: add login" || login.Tag != "feature" || login.PullRequest != "#10" || login.Branch != "login" {
+		t.Errorf("Unexpected merge entry: %+v", login)
+	}
+	if login.PullRequestURL != "https://github.com/acme/app/pull/10" {
+		t.Errorf("Unexpected pull request URL: %s", login.PullRequestURL)
+	}
+	if got := hashes(login.Commits); !reflect.DeepEqual(got, []string{"a1"}) {
+		t.Errorf("login merge should carry a1, got %v", got)
+	}
+
+	// an untyped title takes the most significant type it brings in
+	deps := rel.Entries[2]
+	if deps.Tag != "fix" {
+		t.Errorf("Expected fix for untyped merge, got %s", deps.Tag)
+	}
+	if got := hashes(deps.Commits); !reflect.DeepEqual(got, []string{"b1", "b2"}) {
+		t.Errorf("deps merge should carry b1 and b2, got %v", got)
+	}
+
+	md := gen.ExportMarkdown(releases)
+	for _, want := range []string{
+		"- **feature:** feat: add login ([#10](https://github.com/acme/app/pull/10)) ([`m1`](https://github.com/acme/app/commit/m1))\n  - feat: login form",
+		"- **fix:** Update dependencies ([#12](https://github.com/acme/app/pull/12))",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Missing %q in:\n%s", want, md)
+		}
+	}
+	if strings.Contains(md, "Merge pull request") {
+		t.Errorf("Merge subjects should be replaced by titles:\n%s", md)
+	}
+
+	gen = NewGenerator(pullRequestHistory(), &workspace.Meta{})
+	gen.Config = &config.Config{Changelog: config.Changelog{FirstParent: true, HideMergedCommits: true}}
+	releases, _ = gen.GenerateFull(context.Background())
+	for _, e := range releases[0].Entries {
+		if len(e.Commits) > 0 {
+			t.Errorf("Nested commits should be hidden, got %v on %s", hashes(e.Commits), e.Hash)
+		}
+	}
+
+	// the commits of a hidden merge come back to the top level
+	gen = NewGenerator(pullRequestHistory(), &workspace.Meta{Hidden: map[string]bool{"m2": true}})
+	gen.Config = &config.Config{Changelog: config.Changelog{FirstParent: true}}
+	releases, _ = gen.GenerateFull(context.Background())
+	if got := hashes(releases[0].Entries); !reflect.DeepEqual(got, []string{"m1", "d1", "b1", "b2"}) {
+		t.Errorf("Expected b1 and b2 promoted, got %v", got)
+	}
+}
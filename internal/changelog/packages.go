@@ -0,0 +1,91 @@
+package changelog
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"tutugit/internal/config"
+)
+
+// ForPackage -> a copy of the generator limited to one package of a monorepo: its releases
+// are the tags of the package, and its entries the commits touching files below its path.
+func (g *Generator) ForPackage(name string) (*Generator, error) {
+	if g.Config != nil {
+		for i := range g.Config.Packages {
+			if pkg := &g.Config.Packages[i]; pkg.Name == name {
+				scoped := *g
+				scoped.Package = pkg
+				if g.CachePath != "" {
+					ext := path.Ext(g.CachePath)
+					scoped.CachePath = strings.TrimSuffix(g.CachePath, ext) + "-" + strings.ReplaceAll(name, "/", "-") + ext
+				}
+				return &scoped, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("unknown package %q: declare it under packages in config.yml", name)
+}
+
+// PackageNames -> the names of the configured packages, in config order.
+func PackageNames(cfg *config.Config) []string {
+	if cfg == nil {
+		return nil
+	}
+	names := make([]string, len(cfg.Packages))
+	for i, pkg := range cfg.Packages {
+		names[i] = pkg.Name
+	}
+	return names
+}
+
+// ChangelogFile -> the Keep a Changelog file of the releases: the one of the package, else
+// CHANGELOG.md at the root of the repository.
+func (g *Generator) ChangelogFile() string {
+	if g.Package == nil {
+		return "CHANGELOG.md"
+	}
+	if g.Package.Changelog != "" {
+		return g.Package.Changelog
+	}
+	return path.Join(packagePath(g.Package), "CHANGELOG.md")
+}
+
+// packageTagPattern -> the tag pattern of a package, "<name>/v*" when none is configured.
+func packageTagPattern(pkg *config.Package) string {
+	if pkg.TagPattern != "" {
+		return pkg.TagPattern
+	}
+	return pkg.Name + "/v*"
+}
+
+// packagePath -> the directory of a package, slash-separated and without trailing slash.
+// "." stands for the whole repository.
+func packagePath(pkg *config.Package) string {
+	return path.Clean(strings.TrimPrefix(pkg.Path, "./"))
+}
+
+// inPackage -> reports whether any of the files lies below the directory of the package.
+func inPackage(pkg *config.Package, files []string) bool {
+	dir := packagePath(pkg)
+	for _, f := range files {
+		if dir == "." || f == dir || strings.HasPrefix(f, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// packagesOf -> the packages a commit belongs to by the files it touches, in config order.
+func packagesOf(cfg *config.Config, files []string) []string {
+	if cfg == nil {
+		return nil
+	}
+	var names []string
+	for i := range cfg.Packages {
+		if inPackage(&cfg.Packages[i], files) {
+			names = append(names, cfg.Packages[i].Name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,107 @@
+package changelog
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"tutugit/internal/config"
+	"tutugit/internal/git"
+	"tutugit/internal/workspace"
+)
+
+// monorepoHistory -> c1 (api/v1.0.0) ── c2 (web/v0.1.0) ── c3 ── c4 ── c5 (HEAD), with the
+// api in services/api and the web app in web.
+func monorepoHistory() (*git.MockRunner, *config.Config) {
+	mock := git.NewMockRunner()
+	mock.Tags = []string{"web/v0.1.0", "api/v1.0.0"}
+	mock.TagCommits = map[string]string{"web/v0.1.0": "c2", "api/v1.0.0": "c1"}
+	mock.Commits = []git.Commit{
+		{Hash: "c5", ShortHash: "c5", Message: "docs: readme", Parents: []string{"c4"}},
+		{Hash: "c4", ShortHash: "c4", Message: "feat!: shared protocol", Parents: []string{"c3"}},
+		{Hash: "c3", ShortHash: "c3", Message: "fix(api): timeout", Parents: []string{"c2"}},
+		{Hash: "c2", ShortHash: "c2", Message: "feat: web page", Parents: []string{"c1"}},
+		{Hash: "c1", ShortHash: "c1", Message: "feat: api initial"},
+	}
+	mock.CommitStats = map[string][]git.FileStat{
+		"c5": {{Path: "README.md"}},
+		"c4": {{Path: "services/api/proto.go"}, {Path: "web/proto.ts"}},
+		"c3": {{Path: "services/api/client.go"}},
+		"c2": {{Path: "web/index.html"}},
+		"c1": {{Path: "services/api/main.go"}},
+	}
+	cfg := &config.Config{Packages: []config.Package{
+		{Name: "api", Path: "services/api", TagPattern: "api/v*"},
+		{Name: "web", Path: "./web/"},
+	}}
+	return mock, cfg
+}
+
+func TestGenerateFull_Packages(t *testing.T) {
+	ctx := context.Background()
+	mock, cfg := monorepoHistory()
+	gen := NewGenerator(mock, &workspace.Meta{})
+	gen.Config = cfg
+
+	// the whole repository has no release of its own, each commit names its packages
+	releases, err := gen.GenerateFull(ctx)
+	if err != nil {
+		t.Fatalf("GenerateFull failed: %v", err)
+	}
+	if len(releases) != 1 || len(releases[0].Entries) != 5 {
+		t.Fatalf("Expected every commit unreleased at the root, got %+v", releases)
+	}
+	for _, e := range releases[0].Entries {
+		if e.Hash == "c4" && !reflect.DeepEqual(e.Packages, []string{"api", "web"}) {
+			t.Errorf("Expected c4 in both packages, got %v", e.Packages)
+		}
+		if e.Hash == "c5" && e.Packages != nil {
+			t.Errorf("Expected c5 in no package, got %v", e.Packages)
+		}
+	}
+
+	tests := []struct {
+		pkg        string
+		unreleased []string
+		previous   string
+		next       string
+		changelog  string
+		files      int // files of the package changed since its release
+	}{
+		{"api", []string{"c3", "c4"}, "api/v1.0.0", "api/v2.0.0", "services/api/CHANGELOG.md", 2},
+		{"web", []string{"c4"}, "web/v0.1.0", "web/v1.0.0", "web/CHANGELOG.md", 1},
+	}
+	for _, tt := range tests {
+		scoped, err := gen.ForPackage(tt.pkg)
+		if err != nil {
+			t.Fatalf("ForPackage(%s) failed: %v", tt.pkg, err)
+		}
+		releases, err := scoped.GenerateFull(ctx)
+		if err != nil {
+			t.Fatalf("GenerateFull(%s) failed: %v", tt.pkg, err)
+		}
+		if len(releases) != 2 || releases[1].Version != tt.previous || len(releases[1].Entries) != 1 {
+			t.Fatalf("Expected %s and its unreleased changes, got %+v", tt.previous, releases)
+		}
+		if got := hashes(releases[0].Entries); !reflect.DeepEqual(got, tt.unreleased) {
+			t.Errorf("%s: expected %v unreleased, got %v", tt.pkg, tt.unreleased, got)
+		}
+		if stats := releases[0].Stats; stats == nil || stats.Files != tt.files {
+			t.Errorf("%s: expected the stats of %d files of the package, got %+v", tt.pkg, tt.files, stats)
+		}
+		plan, err := scoped.PlanRelease(ctx, "")
+		if err != nil || plan.Tag != tt.next || plan.Previous != tt.previous {
+			t.Errorf("%s: expected %s after %s, got %+v, %v", tt.pkg, tt.next, tt.previous, plan, err)
+		}
+		if got := scoped.ChangelogFile(); got != tt.changelog {
+			t.Errorf("%s: expected changelog %s, got %s", tt.pkg, tt.changelog, got)
+		}
+	}
+
+	if _, err := gen.ForPackage("docs"); err == nil {
+		t.Error("Expected an unknown package to be refused")
+	}
+	if got := changelogLabel("api/v1.2.0"); got != "1.2.0" {
+		t.Errorf("Expected the package left out of the changelog label, got %s", got)
+	}
+}
@@ -0,0 +1,134 @@
+package changelog
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"tutugit/internal/workspace"
+)
+
+// Record -> freezes a tagged release for the metadata: its commits, impact and the notes
+// as generated, so later edits to the metadata or moved tags leave it as published.
+func (g *Generator) Record(rel *Release) (workspace.RecordedRelease, error) {
+	if rel == nil || rel.Version == UnreleasedVersion {
+		return workspace.RecordedRelease{}, fmt.Errorf("only tagged releases can be recorded")
+	}
+
+	frozen := *rel
+	frozen.Recorded = false
+	notes, err := json.Marshal(&frozen)
+	if err != nil {
+		return workspace.RecordedRelease{}, err
+	}
+
+	var commits []string
+	for _, list := range [][]ChangeEntry{rel.Entries, rel.Reverted} {
+		for _, e := range list {
+			commits = append(commits, e.Hash)
+			for _, c := range e.Commits {
+				commits = append(commits, c.Hash)
+			}
+		}
+	}
+
+	return workspace.RecordedRelease{
+		Version:    changelogLabel(rel.Version),
+		Date:       rel.Date,
+		Tag:        rel.Version,
+		Commits:    commits,
+		Impact:     maxImpact(rel.Entries),
+		Intro:      rel.Intro,
+		Highlights: rel.Highlights,
+		Notes:      notes,
+	}, nil
+}
+
+// recordedReleases -> the releases frozen in the metadata, by tag. Records without notes
+// can't be reproduced and are left out, as are the records of other packages.
+func (g *Generator) recordedReleases() map[string]*Release {
+	if g.Meta == nil || len(g.Meta.Releases) == 0 {
+		return nil
+	}
+	res := make(map[string]*Release, len(g.Meta.Releases))
+	patterns := g.tagPatterns()
+	for _, rec := range g.Meta.Releases {
+		var rel Release
+		if len(SelectVersionTags([]string{rec.Tag}, patterns, false)) == 0 {
+			continue
+		}
+		if len(rec.Notes) == 0 || json.Unmarshal(rec.Notes, &rel) != nil {
+			continue
+		}
+		rel.Version, rel.Recorded = rec.Tag, true
+		if rel.Date.IsZero() {
+			rel.Date = rec.Date
+		}
+		res[rec.Tag] = &rel
+	}
+	return res
+}
+
+// shippedCommits -> the commits of the releases recorded in the metadata, which stay out
+// of the other releases when their tag moved or is gone.
+func (g *Generator) shippedCommits() map[string]bool {
+	recorded := g.recordedReleases()
+	if len(recorded) == 0 {
+		return nil
+	}
+	shipped := make(map[string]bool)
+	for _, rec := range g.Meta.Releases {
+		if recorded[rec.Tag] != nil {
+			for _, sha := range rec.Commits {
+				shipped[sha] = true
+			}
+		}
+	}
+	return shipped
+}
+
+// applyRecorded -> replaces computed releases with their recorded copy, and keeps the records
+// whose tag is gone in date order. The computed releases already leave out the shipped commits.
+func (g *Generator) applyRecorded(releases []*Release) []*Release {
+	recorded := g.recordedReleases()
+	if len(recorded) == 0 {
+		return releases
+	}
+
+	var res []*Release
+	for _, rel := range releases {
+		if frozen, ok := recorded[rel.Version]; ok {
+			res = append(res, frozen)
+			delete(recorded, rel.Version)
+			continue
+		}
+		res = append(res, rel)
+	}
+
+	// records of tags that no longer exist
+	var orphans []*Release
+	for _, rel := range recorded {
+		orphans = append(orphans, rel)
+	}
+	sort.Slice(orphans, func(i, j int) bool { return orphans[i].Date.After(orphans[j].Date) })
+	for _, rel := range orphans {
+		i := 0
+		for i < len(res) && (res[i].Version == UnreleasedVersion || !res[i].Date.Before(rel.Date)) {
+			i++
+		}
+		res = append(res[:i], append([]*Release{rel}, res[i:]...)...)
+	}
+	return res
+}
+
+// applyUpcoming -> attaches the notes written for the next release to the unreleased changes,
+// or to the newest release once it is tagged, until it is recorded. They belong to the
+// releases of the whole repository, not to those of a package.
+func (g *Generator) applyUpcoming(releases []*Release) []*Release {
+	if g.Package != nil || g.Meta == nil || g.Meta.Upcoming == nil || g.Meta.Upcoming.IsEmpty() || len(releases) == 0 || releases[0].Recorded {
+		return releases
+	}
+	out := *releases[0]
+	out.Intro, out.Highlights = g.Meta.Upcoming.Intro, g.Meta.Upcoming.Highlights
+	return append([]*Release{&out}, releases[1:]...)
+}
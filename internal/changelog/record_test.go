@@ -0,0 +1,124 @@
+package changelog
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"tutugit/internal/config"
+	"tutugit/internal/git"
+	"tutugit/internal/workspace"
+)
+
+func TestGenerateFull_RecordedReleases(t *testing.T) {
+	history := func() *git.MockRunner {
+		mock := mergeHistory()
+		mock.TagDates = map[string]time.Time{
+			"v1.0.0": time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			"v1.1.0": time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		}
+		return mock
+	}
+	meta := &workspace.Meta{}
+	gen := NewGenerator(history(), meta)
+	releases, err := gen.GenerateFull(context.Background())
+	if err != nil {
+		t.Fatalf("GenerateFull failed: %v", err)
+	}
+	if _, err := gen.Record(releases[0]); err == nil {
+		t.Error("Expected unreleased changes to be refused")
+	}
+	rec, err := gen.Record(releases[1])
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if rec.Version != "1.1.0" || rec.Tag != "v1.1.0" || rec.Impact != "minor" || !reflect.DeepEqual(rec.Commits, []string{"c2", "s1", "m1"}) {
+		t.Fatalf("Unexpected record: %+v", rec)
+	}
+
+	// the metadata changes and the tag moves back to c2 after publishing
+	meta.Releases = []workspace.RecordedRelease{rec}
+	meta.Impacts = map[string]string{"c2": "major"}
+	mock := history()
+	mock.TagCommits["v1.1.0"] = "c2"
+	gen = NewGenerator(mock, meta)
+	releases, err = gen.GenerateFull(context.Background())
+	if err != nil {
+		t.Fatalf("GenerateFull failed: %v", err)
+	}
+	if len(releases) != 3 || !releases[1].Recorded || releases[1].Version != "v1.1.0" {
+		t.Fatalf("Expected the recorded v1.1.0 second, got %+v", releases)
+	}
+	if got := hashes(releases[1].Entries); !reflect.DeepEqual(got, []string{"c2", "s1", "m1"}) || maxImpact(releases[1].Entries) != "minor" {
+		t.Errorf("Recorded release changed: %v, %s", got, maxImpact(releases[1].Entries))
+	}
+	if got := hashes(releases[0].Entries); !reflect.DeepEqual(got, []string{"c3", "f1", "m2"}) {
+		t.Errorf("Expected shipped commits to stay out of unreleased, got %v", got)
+	}
+
+	// the tag is deleted altogether
+	mock = history()
+	mock.Tags = []string{"v1.0.0"}
+	gen = NewGenerator(mock, meta)
+	releases, err = gen.GenerateFull(context.Background())
+	if err != nil {
+		t.Fatalf("GenerateFull failed: %v", err)
+	}
+	var versions []string
+	for _, rel := range releases {
+		versions = append(versions, rel.Version)
+	}
+	if !reflect.DeepEqual(versions, []string{UnreleasedVersion, "v1.1.0", "v1.0.0"}) {
+		t.Errorf("Expected the record of a deleted tag in date order, got %v", versions)
+	}
+}
+
+func TestGenerateFull_RecordedReleaseWithMailmap(t *testing.T) {
+	history := func() *git.MockRunner {
+		mock := mergeHistory()
+		for i := range mock.Commits {
+			mock.Commits[i].Author, mock.Commits[i].Email = "ann", "ann@old.example"
+		}
+		mock.CommitStats = map[string][]git.FileStat{
+			"c2": {{Path: "main.go", Additions: 2}},
+			"c3": {{Path: "fix.go", Additions: 3, Deletions: 1}},
+			"f1": {{Path: "feature.go", Additions: 5}},
+		}
+		return mock
+	}
+	meta := &workspace.Meta{}
+	cfg := &config.Config{Changelog: config.Changelog{Mailmap: map[string]string{"ann@old.example": "Ann Lee <ann@example.com>"}}}
+	gen := NewGenerator(history(), meta)
+	gen.Config = cfg
+	releases, err := gen.GenerateFull(context.Background())
+	if err != nil {
+		t.Fatalf("GenerateFull failed: %v", err)
+	}
+	rec, err := gen.Record(releases[1])
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	// the tag moves back to c2, the rest of the recorded release stays out of unreleased
+	meta.Releases = []workspace.RecordedRelease{rec}
+	mock := history()
+	mock.TagCommits["v1.1.0"] = "c2"
+	gen = NewGenerator(mock, meta)
+	gen.Config = cfg
+	releases, err = gen.GenerateFull(context.Background())
+	if err != nil {
+		t.Fatalf("GenerateFull failed: %v", err)
+	}
+	unreleased := releases[0]
+	if got := hashes(unreleased.Entries); !reflect.DeepEqual(got, []string{"c3", "f1", "m2"}) {
+		t.Fatalf("Expected shipped commits to stay out of unreleased, got %v", got)
+	}
+	want := []Contributor{{Name: "Ann Lee", Email: "ann@example.com", Commits: 2}}
+	if !reflect.DeepEqual(unreleased.Contributors, want) {
+		t.Errorf("Expected the aliased author credited, got %+v", unreleased.Contributors)
+	}
+	if unreleased.Stats == nil || unreleased.Stats.Files != 2 || unreleased.Stats.Additions != 8 {
+		t.Errorf("Expected the size of the unshipped commits, got %+v", unreleased.Stats)
+	}
+}
@@ -0,0 +1,64 @@
+package changelog
+
+import (
+	"sort"
+	"strings"
+)
+
+// revertRef -> what a revert commit points at: the reverted SHA and/or its quoted subject.
+type revertRef struct {
+	sha     string
+	subject string
+}
+
+// applyReverts -> drops revert/original pairs that landed in the same release and returns
+// the remaining reverts separately, so they can be rendered in a "Reverted" section.
+// refs maps the index of each revert entry to what it reverts, and subjects holds the commit
+// subject of each entry: a revert quotes the commit, not the text edited for the changelog.
+// Reverts are matched newest first, so reverting a revert brings the original change back.
+func applyReverts(entries []ChangeEntry, subjects []string, refs map[int]revertRef) (kept, reverted []ChangeEntry) {
+	var order []int
+	for i := range refs {
+		order = append(order, i)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(order)))
+
+	dropped := make(map[int]bool)
+	for _, i := range order {
+		if dropped[i] {
+			continue
+		}
+		ref := refs[i]
+		for j, e := range entries {
+			if j == i || dropped[j] {
+				continue
+			}
+			if (ref.sha != "" && strings.HasPrefix(e.Hash, ref.sha)) ||
+				(ref.sha == "" && ref.subject != "" && subjects[j] == ref.subject) {
+				dropped[i] = true
+				dropped[j] = true
+				break
+			}
+		}
+	}
+
+	for i, e := range entries {
+		if dropped[i] {
+			continue
+		}
+		if _, ok := refs[i]; ok {
+			reverted = append(reverted, e)
+			continue
+		}
+		kept = append(kept, e)
+	}
+	return kept, reverted
+}
+
+// shortSHA -> abbreviates a full commit SHA for display.
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
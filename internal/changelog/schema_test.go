@@ -0,0 +1,226 @@
+package changelog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"tutugit/internal/assets"
+	"tutugit/internal/config"
+	"tutugit/internal/git"
+	"tutugit/internal/workspace"
+)
+
+// schemaValidator -> checks JSON documents against the subset of draft-07 the tutugit
+// schemas use. Unsupported keywords fail loudly, so the schema can't silently outgrow it.
+type schemaValidator struct {
+	root map[string]any
+}
+
+// annotations -> keywords that don't constrain values.
+var annotations = map[string]bool{"$schema": true, "title": true, "description": true, "default": true, "definitions": true}
+
+func loadSchema(t *testing.T, name string) *schemaValidator {
+	t.Helper()
+	data, err := assets.SchemasFS.ReadFile("schemas/" + name)
+	if err != nil {
+		t.Fatalf("reading %s: %v", name, err)
+	}
+	var root map[string]any
+	if err := json.Unmarshal(data, &root); err != nil {
+		t.Fatalf("parsing %s: %v", name, err)
+	}
+	return &schemaValidator{root: root}
+}
+
+func (v *schemaValidator) validate(data []byte) []string {
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return []string{err.Error()}
+	}
+	return v.check(v.root, doc, "$")
+}
+
+func (v *schemaValidator) check(schema map[string]any, value any, at string) []string {
+	if ref, ok := schema["$ref"].(string); ok {
+		name := strings.TrimPrefix(ref, "#/definitions/")
+		def, ok := v.root["definitions"].(map[string]any)[name].(map[string]any)
+		if !ok {
+			return []string{at + ": unknown $ref " + ref}
+		}
+		return v.check(def, value, at)
+	}
+
+	var errs []string
+	fail := func(format string, args ...any) { errs = append(errs, at+": "+fmt.Sprintf(format, args...)) }
+
+	keys := make([]string, 0, len(schema))
+	for k := range schema {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		rule := schema[key]
+		switch key {
+		case "type":
+			types, ok := rule.([]any)
+			if !ok {
+				types = []any{rule}
+			}
+			matched := false
+			for _, tp := range types {
+				matched = matched || hasType(value, tp.(string))
+			}
+			if !matched {
+				fail("expected %v, got %T", rule, value)
+				return errs
+			}
+		case "enum":
+			found := false
+			for _, allowed := range rule.([]any) {
+				found = found || allowed == value
+			}
+			if !found {
+				fail("%v is not one of %v", value, rule)
+			}
+		case "minimum":
+			if n, ok := value.(float64); ok && n < rule.(float64) {
+				fail("%v is below %v", n, rule)
+			}
+		case "format":
+			if s, ok := value.(string); ok && rule == "date-time" {
+				if _, err := time.Parse(time.RFC3339, s); err != nil {
+					fail("%q is not a date-time", s)
+				}
+			}
+		case "required":
+			obj, _ := value.(map[string]any)
+			for _, name := range rule.([]any) {
+				if _, ok := obj[name.(string)]; obj != nil && !ok {
+					fail("missing %q", name)
+				}
+			}
+		case "properties", "additionalProperties":
+			obj, ok := value.(map[string]any)
+			if !ok || key == "additionalProperties" {
+				continue // checked once, with the properties
+			}
+			props := rule.(map[string]any)
+			for name, field := range obj {
+				if sub, ok := props[name].(map[string]any); ok {
+					errs = append(errs, v.check(sub, field, at+"."+name)...)
+					continue
+				}
+				switch extra := schema["additionalProperties"].(type) {
+				case bool:
+					if !extra {
+						errs = append(errs, at+": unexpected field "+name)
+					}
+				case map[string]any:
+					errs = append(errs, v.check(extra, field, at+"."+name)...)
+				}
+			}
+		case "items":
+			list, _ := value.([]any)
+			for i, item := range list {
+				errs = append(errs, v.check(rule.(map[string]any), item, fmt.Sprintf("%s[%d]", at, i))...)
+			}
+		default:
+			if !annotations[key] {
+				fail("unsupported keyword %q", key)
+			}
+		}
+	}
+	return errs
+}
+
+func hasType(value any, tp string) bool {
+	switch tp {
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == float64(int64(n))
+	case "null":
+		return value == nil
+	}
+	return false
+}
+
+func TestExportJSON_Schema(t *testing.T) {
+	schema := loadSchema(t, "release.schema.json")
+
+	// a history with merges, issues, stats, workspaces and contributors
+	mock := pullRequestHistory()
+	mock.Commits[1].Body = "fix: pin yaml version\n\nFixes #8\n\nBREAKING CHANGE: yaml 2 is required"
+	mock.Commits[1].Author, mock.Commits[1].Email = "Ann", "ann@acme.io"
+	mock.CommitStats = map[string][]git.FileStat{
+		"b2": {{Path: "go.mod", Additions: 1, Deletions: 1}},
+		"a1": {{Path: "web/login.go", Additions: 40}, {Path: "web/logo.png", Binary: true}},
+	}
+	meta := &workspace.Meta{
+		Workspaces: []workspace.Workspace{{Name: "Web", Description: "Frontend", Commits: []string{"a1", "m1"}}},
+		Upcoming:   &workspace.ReleaseNotes{Intro: "Logins are back.", Highlights: []string{"Login page"}},
+	}
+
+	for _, firstParent := range []bool{false, true} {
+		gen := NewGenerator(mock, meta)
+		gen.Version = "1.2.3"
+		gen.Config = &config.Config{
+			Project:   config.Project{Name: "app", Description: "An app"},
+			Changelog: config.Changelog{FirstParent: firstParent},
+		}
+		releases, err := gen.GenerateFull(context.Background())
+		if err != nil {
+			t.Fatalf("GenerateFull failed: %v", err)
+		}
+		data, err := gen.ExportJSON(releases)
+		if err != nil {
+			t.Fatalf("ExportJSON failed: %v", err)
+		}
+		if errs := schema.validate(data); len(errs) > 0 {
+			t.Errorf("first_parent=%v: export doesn't match the schema:\n%s\n%s", firstParent, strings.Join(errs, "\n"), data)
+		}
+	}
+
+	// hand-made releases cover the fields the history above doesn't reach
+	data, err := (&Generator{}).ExportJSON(append(exportReleases(), &Release{Version: "v0.1.0"}))
+	if err != nil {
+		t.Fatalf("ExportJSON failed: %v", err)
+	}
+	if errs := schema.validate(data); len(errs) > 0 {
+		t.Errorf("export doesn't match the schema:\n%s\n%s", strings.Join(errs, "\n"), data)
+	}
+}
+
+func TestSchemaValidator_Rejects(t *testing.T) {
+	schema := loadSchema(t, "release.schema.json")
+	for name, doc := range map[string]string{
+		"missing envelope":  `[{"version": "v1", "date": "2024-01-01T00:00:00Z", "entries": []}]`,
+		"unknown field":     `{"schema_version": 1, "project": {"name": ""}, "generator": {"name": "tutugit", "version": ""}, "releases": [], "extra": 1}`,
+		"bad impact":        `{"schema_version": 1, "project": {"name": ""}, "generator": {"name": "tutugit", "version": ""}, "releases": [{"version": "v1", "date": "2024-01-01T00:00:00Z", "entries": [{"hash": "a", "short_hash": "a", "author": "", "subject": "", "tag": "", "impact": "huge", "date": "2024-01-01T00:00:00Z"}]}]}`,
+		"bad date":          `{"schema_version": 1, "project": {"name": ""}, "generator": {"name": "tutugit", "version": ""}, "releases": [{"version": "v1", "date": "yesterday", "entries": []}]}`,
+		"null entries list": `{"schema_version": 1, "project": {"name": ""}, "generator": {"name": "tutugit", "version": ""}, "releases": [{"version": "v1", "date": "2024-01-01T00:00:00Z", "entries": null}]}`,
+	} {
+		if errs := schema.validate([]byte(doc)); len(errs) == 0 {
+			t.Errorf("%s: expected validation errors", name)
+		}
+	}
+}
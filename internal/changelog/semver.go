@@ -0,0 +1,186 @@
+package changelog
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SemVer -> a parsed semantic version (https://semver.org), e.g. "1.4.0-rc.1+build.5".
+type SemVer struct {
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease []string // dot-separated identifiers, e.g. ["rc", "1"]
+	Build      string   // build metadata, ignored when comparing
+}
+
+// ParseSemVer -> parses a version with an optional "v" prefix. Missing minor and patch
+// numbers are not accepted, so "v1" or "2024-01-deploy" are rejected.
+func ParseSemVer(s string) (SemVer, error) {
+	var v SemVer
+	text := s
+	if strings.HasPrefix(text, "v") || strings.HasPrefix(text, "V") {
+		text = text[1:]
+	}
+
+	if i := strings.Index(text, "+"); i >= 0 {
+		v.Build = text[i+1:]
+		text = text[:i]
+		if v.Build == "" {
+			return SemVer{}, fmt.Errorf("invalid version %q: empty build metadata", s)
+		}
+	}
+	if i := strings.Index(text, "-"); i >= 0 {
+		pre := text[i+1:]
+		text = text[:i]
+		if pre == "" {
+			return SemVer{}, fmt.Errorf("invalid version %q: empty pre-release", s)
+		}
+		v.Prerelease = strings.Split(pre, ".")
+		for _, id := range v.Prerelease {
+			if id == "" {
+				return SemVer{}, fmt.Errorf("invalid version %q: empty pre-release identifier", s)
+			}
+		}
+	}
+
+	parts := strings.Split(text, ".")
+	if len(parts) != 3 {
+		return SemVer{}, fmt.Errorf("invalid version %q: expected major.minor.patch", s)
+	}
+	nums := []*int{&v.Major, &v.Minor, &v.Patch}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 || (len(p) > 1 && p[0] == '0') {
+			return SemVer{}, fmt.Errorf("invalid version %q: bad number %q", s, p)
+		}
+		*nums[i] = n
+	}
+	return v, nil
+}
+
+// IsPrerelease -> reports whether the version has a pre-release part.
+func (v SemVer) IsPrerelease() bool {
+	return len(v.Prerelease) > 0
+}
+
+// String -> the canonical form, without "v" prefix.
+func (v SemVer) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if len(v.Prerelease) > 0 {
+		s += "-" + strings.Join(v.Prerelease, ".")
+	}
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+	return s
+}
+
+// Compare -> returns -1, 0 or 1 following semver precedence: pre-releases sort before
+// their final version, numeric identifiers compare numerically and below alphanumeric ones.
+func (v SemVer) Compare(o SemVer) int {
+	for _, d := range []int{v.Major - o.Major, v.Minor - o.Minor, v.Patch - o.Patch} {
+		if d != 0 {
+			return sign(d)
+		}
+	}
+
+	switch {
+	case !v.IsPrerelease() && !o.IsPrerelease():
+		return 0
+	case !v.IsPrerelease():
+		return 1
+	case !o.IsPrerelease():
+		return -1
+	}
+
+	for i := 0; i < len(v.Prerelease) && i < len(o.Prerelease); i++ {
+		a, b := v.Prerelease[i], o.Prerelease[i]
+		an, aErr := strconv.Atoi(a)
+		bn, bErr := strconv.Atoi(b)
+		switch {
+		case aErr == nil && bErr == nil:
+			if an != bn {
+				return sign(an - bn)
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(a, b); c != 0 {
+				return c
+			}
+		}
+	}
+	return sign(len(v.Prerelease) - len(o.Prerelease))
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
+
+// VersionTag -> a git tag that names a release.
+type VersionTag struct {
+	Name    string // the tag as in git, e.g. "pkg/api/v1.2.0"
+	Version SemVer
+}
+
+// MatchTagPattern -> matches a tag against a glob such as "v*" or "pkg/api/v*" and parses
+// the part after the pattern's literal prefix as a version. Without "*", the pattern only
+// matches the tag verbatim.
+func MatchTagPattern(pattern, tag string) (SemVer, bool) {
+	if ok, err := path.Match(pattern, tag); err != nil || !ok {
+		return SemVer{}, false
+	}
+	prefix := pattern
+	if i := strings.IndexAny(pattern, "*?["); i >= 0 {
+		prefix = pattern[:i]
+	}
+	v, err := ParseSemVer(strings.TrimPrefix(tag, prefix))
+	if err != nil {
+		return SemVer{}, false
+	}
+	return v, true
+}
+
+// SelectVersionTags -> keeps the tags that match one of the patterns (any semver tag, with or
+// without "v", when no pattern is given), optionally drops pre-releases, and sorts the rest
+// from newest to oldest.
+func SelectVersionTags(tags, patterns []string, skipPrereleases bool) []VersionTag {
+	var selected []VersionTag
+	for _, tag := range tags {
+		var v SemVer
+		matched := false
+		if len(patterns) == 0 {
+			parsed, err := ParseSemVer(tag)
+			v, matched = parsed, err == nil
+		}
+		for _, p := range patterns {
+			if v, matched = MatchTagPattern(p, tag); matched {
+				break
+			}
+		}
+		if !matched || (skipPrereleases && v.IsPrerelease()) {
+			continue
+		}
+		selected = append(selected, VersionTag{Name: tag, Version: v})
+	}
+
+	sort.SliceStable(selected, func(i, j int) bool {
+		if c := selected[i].Version.Compare(selected[j].Version); c != 0 {
+			return c > 0
+		}
+		return selected[i].Name < selected[j].Name
+	})
+	return selected
+}
@@ -0,0 +1,128 @@
+package changelog
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"tutugit/internal/config"
+	"tutugit/internal/git"
+	"tutugit/internal/workspace"
+)
+
+func TestParseSemVer(t *testing.T) {
+	v, err := ParseSemVer("v1.4.0-rc.1+build.5")
+	if err != nil {
+		t.Fatalf("ParseSemVer failed: %v", err)
+	}
+	if v.Major != 1 || v.Minor != 4 || v.Patch != 0 || !reflect.DeepEqual(v.Prerelease, []string{"rc", "1"}) || v.Build != "build.5" {
+		t.Errorf("unexpected parse: %+v", v)
+	}
+	if v.String() != "1.4.0-rc.1+build.5" {
+		t.Errorf("unexpected String: %s", v.String())
+	}
+
+	for _, bad := range []string{"", "v1", "1.2", "1.2.3.4", "deploy-prod", "1.02.0", "1.2.3-", "1.2.3-rc..1", "1.2.3+"} {
+		if _, err := ParseSemVer(bad); err == nil {
+			t.Errorf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestSemVer_Compare(t *testing.T) {
+	// precedence example from the semver spec, in ascending order
+	ordered := []string{
+		"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta", "1.0.0-beta.2",
+		"1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "1.0.1", "1.2.0", "1.10.0", "2.0.0",
+	}
+	for i := 0; i < len(ordered)-1; i++ {
+		a, _ := ParseSemVer(ordered[i])
+		b, _ := ParseSemVer(ordered[i+1])
+		if a.Compare(b) != -1 || b.Compare(a) != 1 {
+			t.Errorf("expected %s < %s", ordered[i], ordered[i+1])
+		}
+	}
+
+	a, _ := ParseSemVer("1.0.0+linux")
+	b, _ := ParseSemVer("v1.0.0+darwin")
+	if a.Compare(b) != 0 {
+		t.Error("build metadata must not affect precedence")
+	}
+}
+
+func TestMatchTagPattern(t *testing.T) {
+	tests := []struct {
+		pattern, tag string
+		ok           bool
+	}{
+		{"v*", "v1.2.0", true},
+		{"v*", "1.2.0", false},
+		{"v*", "vnext", false},
+		{"pkg/api/v*", "pkg/api/v0.3.1", true},
+		{"pkg/api/v*", "pkg/web/v0.3.1", false},
+		{"pkg/api/v*", "v0.3.1", false},
+		{"*", "1.0.0", true},
+		{"*", "deploy-prod", false},
+	}
+	for _, tt := range tests {
+		if _, ok := MatchTagPattern(tt.pattern, tt.tag); ok != tt.ok {
+			t.Errorf("MatchTagPattern(%q, %q) = %v, want %v", tt.pattern, tt.tag, ok, tt.ok)
+		}
+	}
+}
+
+func TestSelectVersionTags(t *testing.T) {
+	tags := []string{"v1.10.0", "deploy-prod", "v1.9.0", "v2.0.0-rc.1", "v1.2.0", "pkg/api/v5.0.0", "2.0.0-beta"}
+
+	names := func(vts []VersionTag) []string {
+		var out []string
+		for _, vt := range vts {
+			out = append(out, vt.Name)
+		}
+		return out
+	}
+
+	got := names(SelectVersionTags(tags, nil, false))
+	want := []string{"v2.0.0-rc.1", "2.0.0-beta", "v1.10.0", "v1.9.0", "v1.2.0"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("default selection = %v, want %v", got, want)
+	}
+
+	got = names(SelectVersionTags(tags, []string{"v*"}, true))
+	want = []string{"v1.10.0", "v1.9.0", "v1.2.0"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("v* without pre-releases = %v, want %v", got, want)
+	}
+
+	got = names(SelectVersionTags(tags, []string{"pkg/api/v*"}, false))
+	if !reflect.DeepEqual(got, []string{"pkg/api/v5.0.0"}) {
+		t.Errorf("pkg/api/v* = %v", got)
+	}
+}
+
+func TestGenerateFull_ReleaseTags(t *testing.T) {
+	mock := git.NewMockRunner()
+	mock.Tags = []string{"v1.10.0", "v1.9.0", "deploy-prod", "v2.0.0", "v1.10.1-rc.1"}
+	mock.Unreachable = map[string]bool{"v2.0.0": true} // lives on another branch
+	mock.Commits = []git.Commit{{Hash: "h1", ShortHash: "h1", Message: "feat: something"}}
+
+	gen := NewGenerator(mock, &workspace.Meta{})
+	gen.Config = &config.Config{Changelog: config.Changelog{TagPatterns: []string{"v*"}, SkipPrereleases: true}}
+
+	releases, err := gen.GenerateFull(context.Background())
+	if err != nil {
+		t.Fatalf("GenerateFull failed: %v", err)
+	}
+
+	var versions []string
+	for _, rel := range releases {
+		versions = append(versions, rel.Version)
+	}
+	want := []string{UnreleasedVersion, "v1.10.0", "v1.9.0"}
+	if !reflect.DeepEqual(versions, want) {
+		t.Errorf("releases = %v, want %v", versions, want)
+	}
+	if releases[1].Previous != "v1.9.0" {
+		t.Errorf("v1.10.0 should be compared with v1.9.0, got %q", releases[1].Previous)
+	}
+}
@@ -0,0 +1,151 @@
+package changelog
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+
+	"tutugit/internal/config"
+	"tutugit/internal/git"
+)
+
+// topDirectories -> how many directories a release lists in its stats.
+const topDirectories = 5
+
+// DiffStats -> the size of a change: files touched and lines added and deleted.
+type DiffStats struct {
+	Files       int       `json:"files"`
+	Additions   int       `json:"additions"`
+	Deletions   int       `json:"deletions"`
+	Directories []DirStat `json:"directories,omitempty"` // most changed directories, on releases and workspaces
+}
+
+// DirStat -> the changes made within one directory.
+type DirStat struct {
+	Path      string `json:"path"`
+	Files     int    `json:"files"`
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+}
+
+// commitStats -> the file stats of the commits in a single call. Git is only asked when the
+// sizes are shown or when the files touched matter, see needsFiles.
+func (g *Generator) commitStats(ctx context.Context, commits []git.Commit) (map[string][]git.FileStat, error) {
+	if len(commits) == 0 || (g.NoStats && !g.needsFiles()) {
+		return nil, nil
+	}
+	hashes := make([]string, len(commits))
+	for i, c := range commits {
+		hashes[i] = c.Hash
+	}
+	stats, err := g.Git.GetCommitStats(ctx, hashes)
+	if err != nil {
+		return nil, fmt.Errorf("could not list the files of the commits: %w", err)
+	}
+	return stats, nil
+}
+
+// needsFiles -> reports whether the files touched by commits matter besides their sizes: to
+// apply path exclusions or to tell the packages of a monorepo apart.
+func (g *Generator) needsFiles() bool {
+	if g.Package != nil {
+		return true
+	}
+	return g.Config != nil && (len(g.Config.Packages) > 0 || len(g.Config.Changelog.Exclude.Paths) > 0)
+}
+
+// packageStats -> the file stats of the commits, limited to the files of a package.
+func packageStats(pkg *config.Package, stats map[string][]git.FileStat) map[string][]git.FileStat {
+	res := make(map[string][]git.FileStat, len(stats))
+	for hash, files := range stats {
+		for _, fs := range files {
+			if inPackage(pkg, []string{fs.Path}) {
+				res[hash] = append(res[hash], fs)
+			}
+		}
+	}
+	return res
+}
+
+// statPaths -> the paths of the files in stats.
+func statPaths(stats []git.FileStat) []string {
+	paths := make([]string, len(stats))
+	for i, fs := range stats {
+		paths[i] = fs.Path
+	}
+	return paths
+}
+
+// entryStats -> the size of a single commit, nil when it is unknown.
+func entryStats(stats []git.FileStat) *DiffStats {
+	if len(stats) == 0 {
+		return nil
+	}
+	s := &DiffStats{Files: len(stats)}
+	for _, fs := range stats {
+		s.Additions += fs.Additions
+		s.Deletions += fs.Deletions
+	}
+	return s
+}
+
+// aggregateStats -> the size of several commits: files are counted once, lines are summed,
+// and the most changed directories are listed. Nil when no commit has stats.
+func aggregateStats(commits [][]git.FileStat) *DiffStats {
+	s := &DiffStats{}
+	files := make(map[string]bool)
+	dirs := make(map[string]*DirStat)
+	var dirOrder []string
+	for _, stats := range commits {
+		for _, fs := range stats {
+			dir := path.Dir(fs.Path)
+			d, ok := dirs[dir]
+			if !ok {
+				d = &DirStat{Path: dir}
+				dirs[dir] = d
+				dirOrder = append(dirOrder, dir)
+			}
+			if !files[fs.Path] {
+				files[fs.Path] = true
+				s.Files++
+				d.Files++
+			}
+			s.Additions += fs.Additions
+			s.Deletions += fs.Deletions
+			d.Additions += fs.Additions
+			d.Deletions += fs.Deletions
+		}
+	}
+	if s.Files == 0 {
+		return nil
+	}
+
+	for _, dir := range dirOrder {
+		s.Directories = append(s.Directories, *dirs[dir])
+	}
+	sort.SliceStable(s.Directories, func(i, j int) bool {
+		a, b := s.Directories[i], s.Directories[j]
+		if a.Additions+a.Deletions != b.Additions+b.Deletions {
+			return a.Additions+a.Deletions > b.Additions+b.Deletions
+		}
+		return a.Path < b.Path
+	})
+	if len(s.Directories) > topDirectories {
+		s.Directories = s.Directories[:topDirectories]
+	}
+	return s
+}
+
+// releaseStats -> aggregates the stats of the commits kept by the filter (all when nil).
+// Merges are left out, as their changes are already counted in the merged commits.
+func releaseStats(commits []git.Commit, fileStats map[string][]git.FileStat, keep func(hash string) bool) *DiffStats {
+	var lists [][]git.FileStat
+	for _, c := range commits {
+		if len(c.Parents) > 1 || (keep != nil && !keep(c.Hash)) {
+			continue
+		}
+		lists = append(lists, fileStats[c.Hash])
+	}
+	return aggregateStats(lists)
+}
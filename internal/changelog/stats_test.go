@@ -0,0 +1,143 @@
+package changelog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"tutugit/internal/config"
+	"tutugit/internal/git"
+	"tutugit/internal/workspace"
+)
+
+func TestAggregateStats(t *testing.T) {
+	got := aggregateStats([][]git.FileStat{
+		{{Path: "internal/api/server.go", Additions: 10, Deletions: 2}, {Path: "README.md", Additions: 1}},
+		{{Path: "internal/api/server.go", Additions: 5, Deletions: 5}, {Path: "internal/api/routes.go", Additions: 3}},
+		{{Path: "web/logo.png", Binary: true}},
+	})
+	want := &DiffStats{
+		Files: 4, Additions: 19, Deletions: 7,
+		Directories: []DirStat{
+			{Path: "internal/api", Files: 2, Additions: 18, Deletions: 7},
+			{Path: ".", Files: 1, Additions: 1},
+			{Path: "web", Files: 1},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("aggregateStats() = %+v, want %+v", got, want)
+	}
+
+	if aggregateStats(nil) != nil || entryStats(nil) != nil {
+		t.Error("Unknown stats should be nil")
+	}
+}
+
+func TestGenerateRelease_Stats(t *testing.T) {
+	mock := git.NewMockRunner()
+	mock.Commits = []git.Commit{
+		{Hash: "a1", ShortHash: "a1", Parents: []string{"c0"}, Message: "feat: login"},
+		{Hash: "b1", ShortHash: "b1", Parents: []string{"c0"}, Message: "fix: typo"},
+		{Hash: "m1", ShortHash: "m1", Parents: []string{"b1", "a1"}, Message: "Merge branch 'login'"},
+	}
+	mock.CommitStats = map[string][]git.FileStat{
+		"a1": {{Path: "auth/login.go", Additions: 40}, {Path: "auth/login_test.go", Additions: 20}},
+		"b1": {{Path: "README.md", Additions: 1, Deletions: 1}},
+		"m1": {{Path: "auth/login.go", Additions: 40}, {Path: "auth/login_test.go", Additions: 20}},
+	}
+	meta := &workspace.Meta{Workspaces: []workspace.Workspace{{Name: "Auth", Commits: []string{"a1"}}}}
+
+	gen := NewGenerator(mock, meta)
+	rel, err := gen.GenerateRelease(context.Background(), UnreleasedVersion, "", "HEAD")
+	if err != nil {
+		t.Fatalf("GenerateRelease failed: %v", err)
+	}
+
+	if s := rel.Entries[0].Stats; !reflect.DeepEqual(s, &DiffStats{Files: 2, Additions: 60}) {
+		t.Errorf("Unexpected entry stats: %+v", s)
+	}
+	// the merge repeats the changes of a1, it isn't counted twice
+	if s := rel.Stats; s == nil || s.Files != 3 || s.Additions != 61 || s.Deletions != 1 {
+		t.Errorf("Unexpected release stats: %+v", s)
+	}
+	if s := rel.Workspaces[0].Stats; s == nil || s.Files != 2 || s.Additions != 60 || s.Directories[0].Path != "auth" {
+		t.Errorf("Unexpected workspace stats: %+v", s)
+	}
+
+	summary := gen.FormatSummary([]*Release{rel})
+	for _, want := range []string{"Size: 3 files, +61 -1\n", "Top directories: auth (+60 -0), . (+1 -1)\n"} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("Missing %q in:\n%s", want, summary)
+		}
+	}
+	gen.Layout = LayoutWorkspaces
+	if summary := gen.FormatSummary([]*Release{rel}); !strings.Contains(summary, "» Auth [2 files, +60 -0]\n") {
+		t.Errorf("Missing workspace stats in:\n%s", summary)
+	}
+
+	data, err := gen.ExportJSON([]*Release{rel})
+	if err != nil {
+		t.Fatalf("ExportJSON failed: %v", err)
+	}
+	var decoded struct {
+		Releases []struct {
+			Stats DiffStats `json:"stats"`
+		} `json:"releases"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded.Releases[0].Stats.Additions != 61 {
+		t.Errorf("Stats missing from JSON (%v): %s", err, data)
+	}
+}
+
+// statsSpy -> counts the calls for file stats, and fails them when err is set.
+type statsSpy struct {
+	*git.MockRunner
+	calls int
+	err   error
+}
+
+func (s *statsSpy) GetCommitStats(ctx context.Context, hashes []string) (map[string][]git.FileStat, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.MockRunner.GetCommitStats(ctx, hashes)
+}
+
+func TestGenerateRelease_StatsNeeded(t *testing.T) {
+	ctx := context.Background()
+	mock := git.NewMockRunner()
+	mock.Commits = []git.Commit{
+		{Hash: "a1", ShortHash: "a1", Message: "feat: login"},
+		{Hash: "d1", ShortHash: "d1", Message: "fix: typo in guide"},
+	}
+	mock.CommitStats = map[string][]git.FileStat{
+		"a1": {{Path: "login.go", Additions: 3}},
+		"d1": {{Path: "docs/guide.md", Additions: 1}},
+	}
+	spy := &statsSpy{MockRunner: mock}
+	gen := NewGenerator(spy, &workspace.Meta{})
+
+	// without sizes nor path rules, git isn't asked
+	gen.NoStats = true
+	rel, err := gen.GenerateRelease(ctx, UnreleasedVersion, "", "HEAD")
+	if err != nil || spy.calls != 0 || len(rel.Entries) != 2 || rel.Stats != nil || rel.Entries[0].Stats != nil {
+		t.Fatalf("Expected no stats read nor shown, got %d calls, %+v, %v", spy.calls, rel, err)
+	}
+
+	// path rules still need the files, the sizes stay out
+	gen.Config = &config.Config{Changelog: config.Changelog{Exclude: config.Exclude{Paths: []string{"docs/**"}}}}
+	rel, err = gen.GenerateRelease(ctx, UnreleasedVersion, "", "HEAD")
+	if err != nil || spy.calls != 1 || !reflect.DeepEqual(hashes(rel.Entries), []string{"a1"}) || rel.Stats != nil {
+		t.Fatalf("Expected the files read for the path rules only, got %d calls, %+v, %v", spy.calls, rel, err)
+	}
+
+	// a failure is reported rather than dropping the path rules
+	spy.err = errors.New("numstat failed")
+	if _, err := gen.GenerateRelease(ctx, UnreleasedVersion, "", "HEAD"); err == nil || !strings.Contains(err.Error(), "numstat failed") {
+		t.Errorf("Expected the stats error, got %v", err)
+	}
+}
@@ -0,0 +1,315 @@
+package changelog
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"tutugit/internal/assets"
+)
+
+// Names of the templates tutugit renders itself. A file with the same name plus ".tmpl"
+// in the templates directory replaces the built-in layout.
+const (
+	SummaryTemplate  = "summary.txt"
+	MarkdownTemplate = "release.md"
+)
+
+// TemplateExt -> the extension of template files in .tutugit/templates/.
+const TemplateExt = ".tmpl"
+
+// Layouts of the built-in templates.
+const (
+	LayoutTags       = "tags"       // one list per release, grouped by tag
+	LayoutWorkspaces = "workspaces" // one section per workspace, grouped by tag underneath
+)
+
+// Template -> a parsed changelog template: text/template for most layouts, html/template
+// for HTML ones so release content is escaped.
+type Template interface {
+	Name() string
+	Execute(w io.Writer, data any) error
+}
+
+// TemplateData -> the value templates are executed with.
+type TemplateData struct {
+	Releases []*Release
+	Layout   string // LayoutTags or LayoutWorkspaces
+	Project  string // project name from the config, may be empty
+}
+
+// TagCount -> the number of entries of a semantic tag, with its plural label.
+type TagCount struct {
+	Tag   string
+	Label string
+	Count int
+}
+
+// WorkspaceGroup -> entries of a release that belong to the same workspace.
+type WorkspaceGroup struct {
+	Workspace   string
+	Description string     // only set by workspaceGroups
+	Stats       *DiffStats // only set by workspaceGroups
+	Entries     []ChangeEntry
+}
+
+// TemplateFuncs -> the helper functions available to changelog templates.
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		// grouping
+		"groupByTag":       groupByType,
+		"groupByScope":     groupByScope,
+		"groupByWorkspace": groupByWorkspace,
+		"workspaceGroups":  workspaceGroups,
+		"workspaces":       workspaceNames,
+
+		// aggregation
+		"maxImpact": maxImpact,
+		"tagCounts": tagCounts,
+		"countTag":  countTag,
+
+		// links and references
+		"hashLink":    mdHash,
+		"link":        mdLink,
+		"linkIssues":  linkIssues,
+		"plainIssues": plainIssues,
+		"shortSHA":    shortSHA,
+		"compareHead": compareHead,
+		"htmlIssues":  htmlIssues,
+		"anchor":      anchor,
+
+		// text
+		"date":      formatDate,
+		"stripType": stripType,
+		"indent":    indent,
+		"plural":    Plural,
+		"join":      strings.Join,
+		"lower":     strings.ToLower,
+		"upper":     strings.ToUpper,
+		"trim":      strings.TrimSpace,
+	}
+}
+
+// ParseTemplate -> parses a changelog template with the helper functions available.
+func ParseTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(TemplateFuncs()).Parse(text)
+}
+
+// parseOverride -> parses a user template, with html/template when it renders HTML
+// (e.g. "release.html") so commit subjects and descriptions can't inject markup.
+func parseOverride(name, text string) (Template, error) {
+	if strings.HasSuffix(name, ".html") {
+		return htmltemplate.New(name).Funcs(htmltemplate.FuncMap(TemplateFuncs())).Parse(text)
+	}
+	return ParseTemplate(name, text)
+}
+
+// LoadTemplates -> parses every "*.tmpl" file of a directory, keyed by file name without
+// the extension (e.g. "release.md"). HTML templates are parsed with html/template. A
+// missing directory yields no templates.
+func LoadTemplates(dir string) (map[string]Template, error) {
+	templates := make(map[string]Template)
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return templates, nil
+		}
+		return nil, err
+	}
+
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), TemplateExt) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, f.Name()))
+		if err != nil {
+			return nil, err
+		}
+		name := strings.TrimSuffix(f.Name(), TemplateExt)
+		tmpl, err := parseOverride(name, string(data))
+		if err != nil {
+			return nil, fmt.Errorf("template %s: %w", f.Name(), err)
+		}
+		templates[name] = tmpl
+	}
+	return templates, nil
+}
+
+// BuiltinTemplate -> parses one of the layouts shipped with tutugit.
+func BuiltinTemplate(name string) (*template.Template, error) {
+	data, err := assets.TemplatesFS.ReadFile("templates/" + name + TemplateExt)
+	if err != nil {
+		return nil, err
+	}
+	return ParseTemplate(name, string(data))
+}
+
+// Render -> executes a template against a list of releases.
+func (g *Generator) Render(tmpl Template, releases []*Release) (string, error) {
+	var b strings.Builder
+	if err := tmpl.Execute(&b, g.templateData(releases)); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// templateData -> the value the templates of this generator are executed with.
+func (g *Generator) templateData(releases []*Release) TemplateData {
+	data := TemplateData{Releases: releases, Layout: g.layout()}
+	if g.Config != nil {
+		data.Project = g.Config.Project.Name
+	}
+	return data
+}
+
+// layout -> the layout chosen for this generator, else the configured one, else LayoutTags.
+func (g *Generator) layout() string {
+	if g.Layout != "" {
+		return g.Layout
+	}
+	if g.Config != nil && g.Config.Changelog.Layout != "" {
+		return g.Config.Changelog.Layout
+	}
+	return LayoutTags
+}
+
+// renderBuiltin -> renders a built-in layout. They are covered by tests, so a failure is
+// reported in the output rather than to every caller.
+func (g *Generator) renderBuiltin(name string, releases []*Release) string {
+	tmpl, err := BuiltinTemplate(name)
+	if err == nil {
+		var out string
+		if out, err = g.Render(tmpl, releases); err == nil {
+			return out
+		}
+	}
+	return fmt.Sprintf("Error rendering %s: %v", name, err)
+}
+
+// maxImpact -> the highest impact among entries ("patch" when there are none).
+func maxImpact(entries []ChangeEntry) string {
+	impactWeight := map[string]int{"patch": 0, "minor": 1, "major": 2}
+	highest := "patch"
+	for _, e := range entries {
+		if impactWeight[e.Impact] > impactWeight[highest] {
+			highest = e.Impact
+		}
+	}
+	return highest
+}
+
+// tagCounts -> counts entries of the well-known tags, in tagOrder. Tags without entries are left out.
+func tagCounts(entries []ChangeEntry) []TagCount {
+	var counts []TagCount
+	for _, o := range tagOrder {
+		if c := countTag(o.tag, entries); c > 0 {
+			counts = append(counts, TagCount{Tag: o.tag, Label: o.label, Count: c})
+		}
+	}
+	return counts
+}
+
+// countTag -> the number of entries with a tag ("other" also counts untagged entries).
+func countTag(tag string, entries []ChangeEntry) int {
+	n := 0
+	for _, e := range entries {
+		if displayTag(e.Tag) == tag {
+			n++
+		}
+	}
+	return n
+}
+
+// workspaceNames -> the workspaces entries belong to, sorted.
+func workspaceNames(entries []ChangeEntry) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, e := range entries {
+		if e.Workspace != "" && !seen[e.Workspace] {
+			seen[e.Workspace] = true
+			names = append(names, e.Workspace)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// groupByWorkspace -> groups entries by workspace, sorted by name, with entries outside
+// any workspace last.
+func groupByWorkspace(entries []ChangeEntry) []WorkspaceGroup {
+	byWorkspace := make(map[string][]ChangeEntry)
+	for _, e := range entries {
+		byWorkspace[e.Workspace] = append(byWorkspace[e.Workspace], e)
+	}
+
+	var groups []WorkspaceGroup
+	for _, name := range workspaceNames(entries) {
+		groups = append(groups, WorkspaceGroup{Workspace: name, Entries: byWorkspace[name]})
+	}
+	if list, ok := byWorkspace[""]; ok {
+		groups = append(groups, WorkspaceGroup{Entries: list})
+	}
+	return groups
+}
+
+// workspaceGroups -> groupByWorkspace for a release, with the workspace descriptions and stats.
+func workspaceGroups(rel *Release) []WorkspaceGroup {
+	groups := groupByWorkspace(rel.Entries)
+	for i := range groups {
+		for _, ws := range rel.Workspaces {
+			if ws.Name == groups[i].Workspace {
+				groups[i].Description = ws.Description
+				groups[i].Stats = ws.Stats
+			}
+		}
+	}
+	return groups
+}
+
+// mdLink -> a Markdown link, or the bare text when there is no URL.
+func mdLink(text, link string) string {
+	if link == "" {
+		return text
+	}
+	return "[" + text + "](" + link + ")"
+}
+
+// formatDate -> an ISO 8601 day ("2006-01-02") in the date's own timezone, "" for zero dates.
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+// indent -> prefixes every non-empty line of text with n spaces, so multi-line
+// descriptions stay inside their list item.
+func indent(n int, text string) string {
+	pad := strings.Repeat(" ", n)
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for i, l := range lines {
+		if strings.TrimSpace(l) != "" {
+			lines[i] = pad + l
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Plural -> the count followed by the singular or plural noun, e.g. "1 commit", "3 commits".
+func Plural(n int, singular, pluralForm string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, singular)
+	}
+	return fmt.Sprintf("%d %s", n, pluralForm)
+}
+
+// stripType -> removes the conventional commit header ("feat(api)!: ") from a subject.
+func stripType(subject string) string {
+	return conventionalPrefixRegex.ReplaceAllString(subject, "")
+}
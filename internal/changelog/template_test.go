@@ -0,0 +1,155 @@
+package changelog
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"tutugit/internal/config"
+	"tutugit/internal/git"
+	"tutugit/internal/workspace"
+)
+
+func TestRender_CustomTemplate(t *testing.T) {
+	rel := &Release{
+		Version: "v2.0.0",
+		Entries: []ChangeEntry{
+			{ShortHash: "aaa1111", Subject: "feat(api)!: drop v1 endpoints", Tag: "feature", Scope: "api", Impact: "major", Workspace: "Core", URL: "https://github.com/o/r/commit/aaa1111"},
+			{ShortHash: "bbb2222", Subject: "fix: handle empty repo", Tag: "fix", Impact: "patch", Workspace: "CLI"},
+			{ShortHash: "ccc3333", Subject: "chore: bump deps", Impact: "patch"},
+		},
+	}
+
+	tmpl, err := ParseTemplate("slack.txt", `{{range .Releases}}*{{.Version}}* ({{maxImpact .Entries}}, {{countTag "feature" .Entries}} feature)
+{{range groupByWorkspace .Entries}}[{{or .Workspace "misc"}}]{{range .Entries}} {{stripType .Subject}} {{hashLink .ShortHash .URL}};{{end}}
+{{end}}{{end}}`)
+	if err != nil {
+		t.Fatalf("ParseTemplate failed: %v", err)
+	}
+
+	g := &Generator{}
+	out, err := g.Render(tmpl, []*Release{rel})
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	want := "*v2.0.0* (major, 1 feature)\n" +
+		"[CLI] handle empty repo `bbb2222`;\n" +
+		"[Core] drop v1 endpoints [`aaa1111`](https://github.com/o/r/commit/aaa1111);\n" +
+		"[misc] bump deps `ccc3333`;\n"
+	if out != want {
+		t.Errorf("unexpected output:\n%q\nwant:\n%q", out, want)
+	}
+}
+
+func TestLoadTemplates(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "release.md.tmpl"), []byte("{{len .Releases}} releases"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not a template"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	templates, err := LoadTemplates(dir)
+	if err != nil {
+		t.Fatalf("LoadTemplates failed: %v", err)
+	}
+	if len(templates) != 1 || templates[MarkdownTemplate] == nil {
+		t.Fatalf("expected only %s, got %v", MarkdownTemplate, templates)
+	}
+
+	out, err := (&Generator{}).Render(templates[MarkdownTemplate], []*Release{{Version: "v1"}, {Version: "v2"}})
+	if err != nil || out != "2 releases" {
+		t.Errorf("unexpected render: %q, %v", out, err)
+	}
+
+	// missing directory is not an error
+	if templates, err := LoadTemplates(filepath.Join(dir, "missing")); err != nil || len(templates) != 0 {
+		t.Errorf("expected no templates for a missing dir, got %v, %v", templates, err)
+	}
+
+	// parse errors name the file
+	if err := os.WriteFile(filepath.Join(dir, "broken.tmpl"), []byte("{{range}}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadTemplates(dir); err == nil || !strings.Contains(err.Error(), "broken.tmpl") {
+		t.Errorf("expected parse error naming broken.tmpl, got %v", err)
+	}
+}
+
+func TestLoadTemplates_HTMLEscaped(t *testing.T) {
+	dir := t.TempDir()
+	text := "{{range .Releases}}{{range .Entries}}<li>{{.Subject}}</li>{{end}}{{end}}"
+	if err := os.WriteFile(filepath.Join(dir, HTMLTemplate+TemplateExt), []byte(text), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	templates, err := LoadTemplates(dir)
+	if err != nil {
+		t.Fatalf("LoadTemplates failed: %v", err)
+	}
+	rels := []*Release{{Version: "v1", Entries: []ChangeEntry{{Subject: "fix: <script>alert(1)</script>"}}}}
+	out, err := (&Generator{}).Render(templates[HTMLTemplate], rels)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if want := "<li>fix: &lt;script&gt;alert(1)&lt;/script&gt;</li>"; out != want {
+		t.Errorf("expected the subject escaped, got %q", out)
+	}
+}
+
+func TestBuiltinTemplates(t *testing.T) {
+	for _, name := range []string{SummaryTemplate, MarkdownTemplate, HTMLTemplate} {
+		if _, err := BuiltinTemplate(name); err != nil {
+			t.Errorf("built-in template %s doesn't parse: %v", name, err)
+		}
+	}
+}
+
+func TestExportMarkdown_WorkspaceLayout(t *testing.T) {
+	mock := git.NewMockRunner()
+	mock.Commits = []git.Commit{
+		{Hash: "h1", ShortHash: "h1", Message: "feat: login page"},
+		{Hash: "h2", ShortHash: "h2", Message: "fix: session timeout"},
+		{Hash: "h3", ShortHash: "h3", Message: "fix: typo in banner"},
+	}
+	meta := &workspace.Meta{Workspaces: []workspace.Workspace{
+		{Name: "Auth", Description: "Sign-in and sessions", Commits: []string{"h1", "h2"}},
+	}}
+
+	gen := NewGenerator(mock, meta)
+	gen.Config = &config.Config{Changelog: config.Changelog{Layout: LayoutWorkspaces}}
+	rel, err := gen.GenerateRelease(context.Background(), UnreleasedVersion, "", "HEAD")
+	if err != nil {
+		t.Fatalf("GenerateRelease failed: %v", err)
+	}
+	if len(rel.Workspaces) != 1 || rel.Workspaces[0] != (WorkspaceInfo{Name: "Auth", Description: "Sign-in and sessions"}) {
+		t.Errorf("Unexpected release workspaces: %+v", rel.Workspaces)
+	}
+
+	md := gen.ExportMarkdown([]*Release{rel})
+	want := "---\n\n" +
+		"### Auth\n\n_Sign-in and sessions_\n\n" +
+		"- **feature:** feat: login page (`h1`)\n" +
+		"- **fix:** fix: session timeout (`h2`)\n\n" +
+		"### Other changes\n\n" +
+		"- **fix:** fix: typo in banner (`h3`)\n"
+	if !strings.Contains(md, want) {
+		t.Errorf("Missing workspace sections in:\n%s", md)
+	}
+	if strings.Contains(md, "**Workspace:**") {
+		t.Errorf("The workspace header line is redundant in this layout:\n%s", md)
+	}
+
+	// the generator's layout wins over the configured one
+	gen.Layout = LayoutTags
+	if md := gen.ExportMarkdown([]*Release{rel}); strings.Contains(md, "### Auth") {
+		t.Errorf("Expected the tags layout:\n%s", md)
+	}
+	if summary := gen.FormatSummary([]*Release{rel}); !strings.Contains(summary, "Workspace: Auth") {
+		t.Errorf("Expected the workspace line in the tags layout:\n%s", summary)
+	}
+}
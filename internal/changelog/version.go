@@ -0,0 +1,277 @@
+package changelog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"tutugit/internal/config"
+)
+
+// Version policies, which turn the impact of the changes into the next version.
+const (
+	PolicySemVer  = "semver"  // major, minor or patch bump
+	PolicySemVer0 = "semver0" // as semver, but breaking changes bump the minor version while it is 0.x
+	PolicyCalVer  = "calver"  // YYYY.MM.patch, the month without leading zero
+)
+
+// Policies -> the supported version policies, the default first.
+var Policies = []string{PolicySemVer, PolicySemVer0, PolicyCalVer}
+
+// Channels -> the usual pre-release channels, from the least to the most stable. Any other
+// name made of letters, digits and hyphens works too.
+var Channels = []string{"alpha", "beta", "rc"}
+
+// channelRegex matches channel names usable as a pre-release identifier, e.g. "beta" or "rc".
+var channelRegex = regexp.MustCompile(`^[0-9A-Za-z-]*[A-Za-z-][0-9A-Za-z-]*$`)
+
+// ErrNothingToRelease -> returned when there are no changes since the last release.
+var ErrNothingToRelease = errors.New("nothing to release")
+
+// ValidatePolicy -> checks a version policy. "" stands for semver.
+func ValidatePolicy(policy string) error {
+	if policy != "" && !slices.Contains(Policies, policy) {
+		return fmt.Errorf("unknown version policy %q: use %s", policy, strings.Join(Policies, ", "))
+	}
+	return nil
+}
+
+// NextVersion -> the final version after base for changes of an impact, following a policy.
+// CalVer versions start over at patch 0 every month.
+func NextVersion(policy string, base SemVer, impact string, now time.Time) SemVer {
+	switch policy {
+	case PolicySemVer0:
+		if base.Major == 0 && impact == "major" {
+			impact = "minor"
+		}
+	case PolicyCalVer:
+		next := SemVer{Major: now.Year(), Minor: int(now.Month())}
+		if base.Major == next.Major && base.Minor == next.Minor {
+			next.Patch = base.Patch + 1
+		}
+		return next
+	}
+	return base.Bump(impact)
+}
+
+// ValidateChannel -> checks that a channel can name pre-releases. "" stands for final releases.
+func ValidateChannel(channel string) error {
+	if channel != "" && !channelRegex.MatchString(channel) {
+		return fmt.Errorf("invalid channel %q: use letters, digits and hyphens, e.g. beta", channel)
+	}
+	return nil
+}
+
+// Core -> the version without pre-release and build metadata, e.g. "1.4.0-beta.3" -> "1.4.0".
+func (v SemVer) Core() SemVer {
+	return SemVer{Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+}
+
+// Channel -> the channel and counter of a pre-release, e.g. "1.4.0-beta.3" -> ("beta", 3).
+// The counter is 0 when the pre-release has none.
+func (v SemVer) Channel() (string, int) {
+	if !v.IsPrerelease() {
+		return "", 0
+	}
+	n := 0
+	if len(v.Prerelease) > 1 {
+		n, _ = strconv.Atoi(v.Prerelease[1])
+	}
+	return v.Prerelease[0], n
+}
+
+// Bump -> the final version that follows v for changes of an impact (patch, minor or major).
+func (v SemVer) Bump(impact string) SemVer {
+	next := v.Core()
+	switch impact {
+	case "major":
+		next = SemVer{Major: next.Major + 1}
+	case "minor":
+		next = SemVer{Major: next.Major, Minor: next.Minor + 1}
+	default:
+		next.Patch++
+	}
+	return next
+}
+
+// VersionPlan -> the version the pending changes would be released as.
+type VersionPlan struct {
+	Previous    string   // newest final release, "" before the first one
+	Version     SemVer   // e.g. 1.4.0-beta.3
+	Tag         string   // e.g. "v1.4.0-beta.3"
+	Impact      string   // highest impact of the changes since Previous
+	Channel     string   // "" for a final release
+	Policy      string   // version policy the version follows
+	Prereleases []string // pre-release tags since Previous, newest first, folded into a final release
+	Major       bool     // the major version changes, CalVer aside
+	Confirm     bool     // a major release, to be confirmed before it is tagged
+
+	VersionFiles []config.VersionFile // rewritten to Version and committed before the tag
+}
+
+// PlanRelease -> computes the next version from the release tags and the impact of the changes
+// since the newest final release, following the configured policy. The base version comes from
+// the tags reachable from HEAD. On a channel, the counter follows the highest tag of that version
+// and channel in the whole repository; without one, pending pre-releases are promoted to the
+// final version.
+func (g *Generator) PlanRelease(ctx context.Context, channel string) (*VersionPlan, error) {
+	if err := ValidateChannel(channel); err != nil {
+		return nil, err
+	}
+	policy, confirmMajor := PolicySemVer, false
+	if g.Config != nil {
+		if g.Config.Release.Policy != "" {
+			policy = g.Config.Release.Policy
+		}
+		confirmMajor = g.Config.Release.ConfirmMajor
+	}
+	if err := ValidatePolicy(policy); err != nil {
+		return nil, err
+	}
+	tags, err := g.Git.GetReachableTags(ctx, "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	all := SelectVersionTags(tags, g.tagPatterns(), false)
+
+	plan := &VersionPlan{Channel: channel, Policy: policy}
+	var final *VersionTag
+	var pending []VersionTag // pre-releases after the newest final release
+	for i := range all {
+		if !all[i].Version.IsPrerelease() {
+			final = &all[i]
+			break
+		}
+		pending = append(pending, all[i])
+		plan.Prereleases = append(plan.Prereleases, all[i].Name)
+	}
+	if final != nil {
+		plan.Previous = final.Name
+	}
+
+	// the impact covers every change since the final release, pre-releases included; sizes
+	// don't weigh on it, so unless the files are needed anyway git doesn't list them
+	gen := g
+	if !g.needsFiles() {
+		lean := *g
+		lean.NoStats, lean.CachePath = true, ""
+		gen = &lean
+	}
+	releases, err := gen.GenerateFull(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var entries []ChangeEntry
+	hasNew := false
+	for _, rel := range releases {
+		if final != nil && rel.Version == final.Name {
+			break
+		}
+		if rel.Version == UnreleasedVersion {
+			hasNew = len(rel.Entries) > 0 || len(rel.Reverted) > 0
+		}
+		entries = append(entries, rel.Entries...)
+	}
+	plan.Impact = maxImpact(entries)
+
+	var base SemVer
+	if final != nil {
+		base = final.Version
+	}
+	now := time.Now
+	if g.now != nil {
+		now = g.now
+	}
+	target := NextVersion(policy, base, plan.Impact, now())
+	// pre-releases never go back, even when the changes turned out smaller than planned
+	if len(pending) > 0 && pending[0].Version.Core().Compare(target) > 0 {
+		target = pending[0].Version.Core()
+	}
+
+	if channel == "" {
+		if !hasNew && len(pending) == 0 {
+			return nil, ErrNothingToRelease
+		}
+		plan.Version = target
+	} else {
+		if !hasNew && (len(pending) == 0 || pending[0].Version.Prerelease[0] == channel) {
+			return nil, ErrNothingToRelease
+		}
+		// the counter follows every tag, including pre-releases tagged on other branches
+		refs, err := g.Git.GetTagRefs(ctx)
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, len(refs))
+		for i, ref := range refs {
+			names[i] = ref.Name
+		}
+		counter := 0
+		for _, t := range SelectVersionTags(names, g.tagPatterns(), false) {
+			if ch, n := t.Version.Channel(); t.Version.Core().Compare(target) == 0 && ch == channel && n > counter {
+				counter = n
+			}
+		}
+		plan.Version = target
+		plan.Version.Prerelease = []string{channel, strconv.Itoa(counter + 1)}
+		if len(pending) > 0 && plan.Version.Compare(pending[0].Version) <= 0 {
+			return nil, fmt.Errorf("%s would sort before %s: channels only move towards the final release (%s)",
+				plan.Version, pending[0].Version, strings.Join(Channels, ", "))
+		}
+		plan.Prereleases = nil
+	}
+
+	plan.Major = policy != PolicyCalVer && plan.Version.Major > base.Major
+	plan.Confirm = plan.Major && confirmMajor
+	plan.Tag = tagPrefix(g.tagPatterns(), all) + plan.Version.String()
+	plan.VersionFiles = g.VersionFiles()
+	return plan, nil
+}
+
+// tagPatterns -> the configured release tag patterns, none meaning any semantic version.
+// A package has its own.
+func (g *Generator) tagPatterns() []string {
+	if g.Package != nil {
+		return []string{packageTagPattern(g.Package)}
+	}
+	if g.Config == nil {
+		return nil
+	}
+	return g.Config.Changelog.TagPatterns
+}
+
+// tagPrefix -> what comes before the version in new tags: the literal part of the first tag
+// pattern, else the prefix of the newest release tag, else "v".
+func tagPrefix(patterns []string, tags []VersionTag) string {
+	if len(patterns) > 0 {
+		if i := strings.IndexAny(patterns[0], "*?["); i >= 0 {
+			return patterns[0][:i]
+		}
+	}
+	if len(tags) > 0 {
+		if prefix, ok := strings.CutSuffix(tags[0].Name, tags[0].Version.String()); ok {
+			return prefix
+		}
+	}
+	return "v"
+}
+
+// foldPrereleases -> leaves out the pre-releases of versions released since, newest first,
+// so their changes are listed under the final release that followed them.
+func foldPrereleases(tags []VersionTag) []VersionTag {
+	var res []VersionTag
+	released := false
+	for _, t := range tags {
+		if t.Version.IsPrerelease() && released {
+			continue
+		}
+		released = released || !t.Version.IsPrerelease()
+		res = append(res, t)
+	}
+	return res
+}
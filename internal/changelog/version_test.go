@@ -0,0 +1,185 @@
+package changelog
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"tutugit/internal/config"
+	"tutugit/internal/git"
+	"tutugit/internal/workspace"
+)
+
+// prereleaseHistory -> c1 (v1.3.0) ── c2 (v1.4.0-beta.1) ── c3 (HEAD)
+func prereleaseHistory() *git.MockRunner {
+	mock := git.NewMockRunner()
+	mock.Tags = []string{"v1.4.0-beta.1", "v1.3.0"}
+	mock.TagCommits = map[string]string{"v1.4.0-beta.1": "c2", "v1.3.0": "c1"}
+	mock.Commits = []git.Commit{
+		{Hash: "c3", ShortHash: "c3", Message: "fix: beta feedback", Parents: []string{"c2"}},
+		{Hash: "c2", ShortHash: "c2", Message: "feat: new exporter", Parents: []string{"c1"}},
+		{Hash: "c1", ShortHash: "c1", Message: "feat: initial"},
+	}
+	return mock
+}
+
+func TestSemVer_BumpAndChannel(t *testing.T) {
+	v, _ := ParseSemVer("1.4.2-beta.3+build.7")
+	for impact, want := range map[string]string{"patch": "1.4.3", "minor": "1.5.0", "major": "2.0.0"} {
+		if got := v.Bump(impact).String(); got != want {
+			t.Errorf("Bump(%s) = %s, want %s", impact, got, want)
+		}
+	}
+	if ch, n := v.Channel(); ch != "beta" || n != 3 || v.Core().String() != "1.4.2" {
+		t.Errorf("Channel() = %s, %d; Core() = %s", ch, n, v.Core())
+	}
+	for channel, valid := range map[string]bool{"": true, "rc": true, "pre-2": true, "7": false, "be.ta": false} {
+		if err := ValidateChannel(channel); (err == nil) != valid {
+			t.Errorf("ValidateChannel(%q) = %v", channel, err)
+		}
+	}
+}
+
+func TestPlanRelease_Channels(t *testing.T) {
+	ctx := context.Background()
+	plan := func(mock *git.MockRunner, channel string) (*VersionPlan, error) {
+		return NewGenerator(mock, &workspace.Meta{}).PlanRelease(ctx, channel)
+	}
+
+	for channel, want := range map[string]string{"beta": "v1.4.0-beta.2", "rc": "v1.4.0-rc.1", "": "v1.4.0"} {
+		p, err := plan(prereleaseHistory(), channel)
+		if err != nil {
+			t.Fatalf("PlanRelease(%q) failed: %v", channel, err)
+		}
+		if p.Tag != want || p.Previous != "v1.3.0" || p.Impact != "minor" {
+			t.Errorf("PlanRelease(%q) = %+v, want %s", channel, p, want)
+		}
+	}
+	if p, _ := plan(prereleaseHistory(), ""); !reflect.DeepEqual(p.Prereleases, []string{"v1.4.0-beta.1"}) {
+		t.Errorf("Expected the beta to be promoted, got %v", p.Prereleases)
+	}
+	if _, err := plan(prereleaseHistory(), "alpha"); err == nil || !strings.Contains(err.Error(), "sort before") {
+		t.Errorf("Expected alpha after beta to be refused, got %v", err)
+	}
+
+	// a beta tagged on another branch still takes its number
+	mock := prereleaseHistory()
+	mock.Tags = append([]string{"v1.4.0-beta.2"}, mock.Tags...)
+	mock.Unreachable = map[string]bool{"v1.4.0-beta.2": true}
+	if p, err := plan(mock, "beta"); err != nil || p.Tag != "v1.4.0-beta.3" {
+		t.Errorf("Expected the next beta after the unreachable one, got %+v, %v", p, err)
+	}
+
+	// nothing new since the beta: it can only move to another channel or be promoted
+	mock = prereleaseHistory()
+	mock.Commits = mock.Commits[1:]
+	if _, err := plan(mock, "beta"); !errors.Is(err, ErrNothingToRelease) {
+		t.Errorf("Expected nothing to release on the same channel, got %v", err)
+	}
+	if p, err := plan(mock, ""); err != nil || p.Tag != "v1.4.0" {
+		t.Errorf("Expected the beta to be promoted as is, got %+v, %v", p, err)
+	}
+
+	// a breaking change after the beta moves the version on
+	mock = prereleaseHistory()
+	mock.Commits[0].Message = "feat!: new config format"
+	if p, _ := plan(mock, "beta"); p == nil || p.Tag != "v2.0.0-beta.1" {
+		t.Errorf("Expected a beta of the next major, got %+v", p)
+	}
+}
+
+func TestPlanRelease_FirstRelease(t *testing.T) {
+	mock := git.NewMockRunner()
+	mock.Commits = []git.Commit{{Hash: "c1", ShortHash: "c1", Message: "feat: initial"}}
+	gen := NewGenerator(mock, &workspace.Meta{})
+	gen.Config = &config.Config{Changelog: config.Changelog{TagPatterns: []string{"api/v*"}}}
+	p, err := gen.PlanRelease(context.Background(), "")
+	if err != nil || p.Tag != "api/v0.1.0" || p.Previous != "" {
+		t.Errorf("Expected api/v0.1.0, got %+v, %v", p, err)
+	}
+}
+
+func TestGenerateFull_FoldsPromotedPrereleases(t *testing.T) {
+	mock := prereleaseHistory()
+	mock.Tags = append([]string{"v1.4.0"}, mock.Tags...)
+	mock.TagCommits["v1.4.0"] = "c3"
+	releases, err := NewGenerator(mock, &workspace.Meta{}).GenerateFull(context.Background())
+	if err != nil {
+		t.Fatalf("GenerateFull failed: %v", err)
+	}
+	if len(releases) != 2 || releases[0].Version != "v1.4.0" || releases[0].Previous != "v1.3.0" {
+		t.Fatalf("Expected the beta folded into v1.4.0, got %d releases", len(releases))
+	}
+	if got := hashes(releases[0].Entries); !reflect.DeepEqual(got, []string{"c2", "c3"}) {
+		t.Errorf("Expected the changes of the beta in v1.4.0, got %v", got)
+	}
+}
+
+func TestNextVersion_Policies(t *testing.T) {
+	now := time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		policy, base, impact, want string
+	}{
+		{PolicySemVer, "0.4.2", "major", "1.0.0"},
+		{PolicySemVer0, "0.4.2", "major", "0.5.0"},
+		{PolicySemVer0, "0.4.2", "patch", "0.4.3"},
+		{PolicySemVer0, "1.4.2", "major", "2.0.0"},
+		{PolicyCalVer, "2025.3.1", "major", "2025.3.2"},
+		{PolicyCalVer, "2025.2.7", "patch", "2025.3.0"},
+		{PolicyCalVer, "0.0.0", "minor", "2025.3.0"},
+	}
+	for _, tt := range tests {
+		base, _ := ParseSemVer(tt.base)
+		if got := NextVersion(tt.policy, base, tt.impact, now).String(); got != tt.want {
+			t.Errorf("NextVersion(%s, %s, %s) = %s, want %s", tt.policy, tt.base, tt.impact, got, tt.want)
+		}
+	}
+	if ValidatePolicy("") != nil || ValidatePolicy("calver") != nil || ValidatePolicy("semver2") == nil {
+		t.Error("ValidatePolicy accepted or refused the wrong policies")
+	}
+}
+
+func TestPlanRelease_PolicyAndMajorGuard(t *testing.T) {
+	mock := func() *git.MockRunner {
+		mock := git.NewMockRunner()
+		mock.Tags = []string{"v0.4.0"}
+		mock.TagCommits = map[string]string{"v0.4.0": "c1"}
+		mock.Commits = []git.Commit{
+			{Hash: "c2", ShortHash: "c2", Message: "feat!: new config format", Parents: []string{"c1"}},
+			{Hash: "c1", ShortHash: "c1", Message: "feat: initial"},
+		}
+		return mock
+	}
+	plan := func(release config.Release) *VersionPlan {
+		gen := NewGenerator(mock(), &workspace.Meta{})
+		gen.Config = &config.Config{Release: release}
+		gen.now = func() time.Time { return time.Date(2025, 11, 2, 0, 0, 0, 0, time.UTC) }
+		p, err := gen.PlanRelease(context.Background(), "")
+		if err != nil {
+			t.Fatalf("PlanRelease(%+v) failed: %v", release, err)
+		}
+		return p
+	}
+
+	if p := plan(config.Release{}); p.Tag != "v1.0.0" || !p.Major || p.Confirm {
+		t.Errorf("Expected an unguarded v1.0.0 with semver, got %+v", p)
+	}
+	if p := plan(config.Release{ConfirmMajor: true}); !p.Confirm {
+		t.Errorf("Expected the major release to need confirmation, got %+v", p)
+	}
+	if p := plan(config.Release{Policy: PolicySemVer0, ConfirmMajor: true}); p.Tag != "v0.5.0" || p.Major || p.Confirm {
+		t.Errorf("Expected v0.5.0 with semver0, got %+v", p)
+	}
+	if p := plan(config.Release{Policy: PolicyCalVer, ConfirmMajor: true}); p.Tag != "v2025.11.0" || p.Confirm {
+		t.Errorf("Expected v2025.11.0 with calver, got %+v", p)
+	}
+
+	gen := NewGenerator(mock(), &workspace.Meta{})
+	gen.Config = &config.Config{Release: config.Release{Policy: "romver"}}
+	if _, err := gen.PlanRelease(context.Background(), ""); err == nil {
+		t.Error("Expected an unknown policy to be refused")
+	}
+}
@@ -0,0 +1,211 @@
+package changelog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"tutugit/internal/config"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ReleaseCommitPrefix -> starts the subject of the commit setting the version files of a
+// release, followed by its tag. Those commits stay out of the release notes.
+const ReleaseCommitPrefix = "chore(release): "
+
+// VersionFiles -> the files to rewrite to the version of a release: the ones of the package,
+// else the ones of the repository.
+func (g *Generator) VersionFiles() []config.VersionFile {
+	if g.Package != nil {
+		return g.Package.VersionFiles
+	}
+	if g.Config == nil {
+		return nil
+	}
+	return g.Config.Release.VersionFiles
+}
+
+// SetVersion -> rewrites the version spelled out in the content of a version file, leaving
+// everything else as is. Fails when the file doesn't spell out a version where expected.
+func SetVersion(data []byte, vf config.VersionFile, version string) ([]byte, error) {
+	switch {
+	case vf.Pattern != "" && vf.Key != "":
+		return nil, fmt.Errorf("%s: set either a pattern or a key, not both", vf.Path)
+	case vf.Pattern != "":
+		return setVersionPattern(data, vf, version)
+	case vf.Key != "":
+		var start, end int
+		var err error
+		switch strings.ToLower(path.Ext(vf.Path)) {
+		case ".json":
+			start, end, err = jsonValueSpan(data, strings.Split(vf.Key, "."))
+		case ".yml", ".yaml":
+			start, end, err = yamlValueSpan(data, strings.Split(vf.Key, "."))
+		default:
+			return nil, fmt.Errorf("%s: keys work in JSON and YAML files, use a pattern", vf.Path)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", vf.Path, err)
+		}
+		return append(append(append([]byte{}, data[:start]...), version...), data[end:]...), nil
+	}
+	return nil, fmt.Errorf("%s: set a pattern or a key", vf.Path)
+}
+
+// setVersionPattern -> replaces the first capture group of every match of the pattern.
+func setVersionPattern(data []byte, vf config.VersionFile, version string) ([]byte, error) {
+	re, err := regexp.Compile(vf.Pattern)
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid pattern: %w", vf.Path, err)
+	}
+	if re.NumSubexp() == 0 {
+		return nil, fmt.Errorf("%s: the pattern needs a capture group around the version", vf.Path)
+	}
+	matches := re.FindAllSubmatchIndex(data, -1)
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("%s: no match for %s", vf.Path, vf.Pattern)
+	}
+	var out []byte
+	last := 0
+	for _, m := range matches {
+		if m[2] < 0 {
+			continue // the group took no part in this match
+		}
+		out = append(out, data[last:m[2]]...)
+		out = append(out, version...)
+		last = m[3]
+	}
+	return append(out, data[last:]...), nil
+}
+
+// jsonValueSpan -> the byte range of the string value at a key path, quotes excluded.
+func jsonValueSpan(data []byte, keys []string) (int, int, error) {
+	type frame struct {
+		object  bool
+		key     string
+		wantKey bool
+	}
+	var stack []frame
+	matches := func() bool {
+		if len(stack) != len(keys) {
+			return false
+		}
+		for i, f := range stack {
+			if !f.object || f.key != keys[i] {
+				return false
+			}
+		}
+		return true
+	}
+	// a value was read, the enclosing object expects a key again
+	valueDone := func() {
+		if n := len(stack); n > 0 && stack[n-1].object {
+			stack[n-1].wantKey = true
+		}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for {
+		before := int(dec.InputOffset()) // end of the previous token
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid JSON: %w", err)
+		}
+		if n := len(stack); n > 0 && stack[n-1].object && stack[n-1].wantKey {
+			if key, ok := tok.(string); ok {
+				stack[n-1].key, stack[n-1].wantKey = key, false
+				continue
+			}
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			if matches() {
+				return 0, 0, fmt.Errorf("%s is not a string", strings.Join(keys, "."))
+			}
+			stack = append(stack, frame{object: tok == json.Delim('{'), wantKey: true})
+			continue
+		case json.Delim('}'), json.Delim(']'):
+			stack = stack[:len(stack)-1]
+			valueDone()
+			continue
+		}
+		if matches() {
+			if _, ok := tok.(string); !ok {
+				return 0, 0, fmt.Errorf("%s is not a string", strings.Join(keys, "."))
+			}
+			// only blanks and the colon separate the key from the opening quote, whereas
+			// the value itself may hold escaped quotes
+			start := before + bytes.IndexByte(data[before:], '"') + 1
+			end := int(dec.InputOffset()) - 1 // closing quote
+			return start, end, nil
+		}
+		valueDone()
+	}
+	return 0, 0, fmt.Errorf("no %s key", strings.Join(keys, "."))
+}
+
+// yamlValueSpan -> the byte range of the scalar value at a key path, quotes excluded.
+func yamlValueSpan(data []byte, keys []string) (int, int, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return 0, 0, fmt.Errorf("invalid YAML: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return 0, 0, fmt.Errorf("no %s key", strings.Join(keys, "."))
+	}
+	node := doc.Content[0]
+	for _, key := range keys {
+		var next *yaml.Node
+		if node.Kind == yaml.MappingNode {
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == key {
+					next = node.Content[i+1]
+					break
+				}
+			}
+		}
+		if next == nil {
+			return 0, 0, fmt.Errorf("no %s key", strings.Join(keys, "."))
+		}
+		node = next
+	}
+	if node.Kind != yaml.ScalarNode {
+		return 0, 0, fmt.Errorf("%s is not a scalar", strings.Join(keys, "."))
+	}
+
+	// yaml positions are 1-based lines and columns
+	start := 0
+	for line := 1; line < node.Line; line++ {
+		i := bytes.IndexByte(data[start:], '\n')
+		if i < 0 {
+			return 0, 0, fmt.Errorf("%s not found", strings.Join(keys, "."))
+		}
+		start += i + 1
+	}
+	// columns count characters, not bytes
+	for col := 1; col < node.Column; col++ {
+		_, size := utf8.DecodeRune(data[start:])
+		if size == 0 {
+			return 0, 0, fmt.Errorf("%s not found", strings.Join(keys, "."))
+		}
+		start += size
+	}
+	if node.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0 {
+		start++
+	}
+	end := start + len(node.Value)
+	if end > len(data) || string(data[start:end]) != node.Value {
+		return 0, 0, fmt.Errorf("%s is not written as a plain value", strings.Join(keys, "."))
+	}
+	return start, end, nil
+}
@@ -0,0 +1,89 @@
+package changelog
+
+import (
+	"testing"
+
+	"tutugit/internal/config"
+)
+
+func TestSetVersion(t *testing.T) {
+	packageJSON := `{
+  "name": "web",
+  "dependencies": { "left-pad": { "version": "1.3.0" } },
+  "version": "1.3.0",
+  "scripts": { "build": "vite" }
+}
+`
+	chartYAML := `apiVersion: v2
+name: api
+app:
+  version: "1.3.0"
+version: 1.3.0 # chart
+`
+	tests := []struct {
+		name    string
+		data    string
+		vf      config.VersionFile
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "pattern",
+			data: "package main\n\nvar version = \"1.3.0\"\n",
+			vf:   config.VersionFile{Path: "constants.go", Pattern: `var version = "(.*)"`},
+			want: "package main\n\nvar version = \"1.4.0\"\n",
+		},
+		{
+			name: "json key",
+			data: packageJSON,
+			vf:   config.VersionFile{Path: "package.json", Key: "version"},
+			want: `{
+  "name": "web",
+  "dependencies": { "left-pad": { "version": "1.3.0" } },
+  "version": "1.4.0",
+  "scripts": { "build": "vite" }
+}
+`,
+		},
+		{
+			name: "nested yaml key",
+			data: chartYAML,
+			vf:   config.VersionFile{Path: "Chart.yaml", Key: "app.version"},
+			want: "apiVersion: v2\nname: api\napp:\n  version: \"1.4.0\"\nversion: 1.3.0 # chart\n",
+		},
+		{
+			name: "plain yaml key",
+			data: chartYAML,
+			vf:   config.VersionFile{Path: "Chart.yml", Key: "version"},
+			want: "apiVersion: v2\nname: api\napp:\n  version: \"1.3.0\"\nversion: 1.4.0 # chart\n",
+		},
+		{
+			name: "json escaped quotes",
+			data: `{"description": "say \"hi\"", "version": "1.3.0-\"rc\""}`,
+			vf:   config.VersionFile{Path: "package.json", Key: "version"},
+			want: `{"description": "say \"hi\"", "version": "1.4.0"}`,
+		},
+		{
+			name: "yaml non-ASCII before the value",
+			data: "app: {nom: \"Zoë Ølund\", version: 1.3.0}\n",
+			vf:   config.VersionFile{Path: "Chart.yaml", Key: "app.version"},
+			want: "app: {nom: \"Zoë Ølund\", version: 1.4.0}\n",
+		},
+		{name: "no capture group", data: "version 1.3.0", vf: config.VersionFile{Path: "VERSION", Pattern: `\d+\.\d+\.\d+`}, wantErr: true},
+		{name: "no match", data: "nothing here", vf: config.VersionFile{Path: "VERSION", Pattern: `v(\d+)`}, wantErr: true},
+		{name: "missing key", data: packageJSON, vf: config.VersionFile{Path: "package.json", Key: "engines.node"}, wantErr: true},
+		{name: "not a string", data: packageJSON, vf: config.VersionFile{Path: "package.json", Key: "scripts"}, wantErr: true},
+		{name: "unknown format", data: "version = \"1.3.0\"", vf: config.VersionFile{Path: "Cargo.toml", Key: "version"}, wantErr: true},
+		{name: "pattern and key", data: packageJSON, vf: config.VersionFile{Path: "package.json", Key: "version", Pattern: "(x)"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := SetVersion([]byte(tt.data), tt.vf, "1.4.0")
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: SetVersion error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && string(got) != tt.want {
+			t.Errorf("%s: SetVersion =\n%s\nwant\n%s", tt.name, got, tt.want)
+		}
+	}
+}
@@ -4,14 +4,20 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 
 	"gopkg.in/yaml.v3"
 )
 
 // Config holds the project-level configuration for tutugit.
 type Config struct {
-	Schema  string  `yaml:"$schema,omitempty"`
-	Project Project `yaml:"project"`
+	Schema    string    `yaml:"$schema,omitempty"`
+	Project   Project   `yaml:"project"`
+	Changelog Changelog `yaml:"changelog,omitempty"`
+	Release   Release   `yaml:"release,omitempty"`
+	// Packages declares the independently versioned parts of a monorepo, each with its own
+	// tags, next version and changelog.
+	Packages []Package `yaml:"packages,omitempty"`
 }
 
 // Project holds basic project metadata.
@@ -20,6 +26,103 @@ type Project struct {
 	Description string `yaml:"description"`
 }
 
+// Changelog holds the settings used when generating release notes.
+type Changelog struct {
+	// IssueURL is the link template for "#123" and "GH-123" references, with {id} replaced
+	// by the issue number. When empty, it is derived from the "origin" remote.
+	IssueURL string `yaml:"issue_url,omitempty"`
+	// IssuePatterns declares additional ticket reference formats, e.g. Jira keys.
+	IssuePatterns []IssuePattern `yaml:"issue_patterns,omitempty"`
+	// Forge overrides the forge detected from the remote host (github, gitlab, bitbucket or
+	// gitea), which is needed for self-hosted instances on custom domains.
+	Forge string `yaml:"forge,omitempty"`
+	// TagPatterns selects which tags are releases, as globs such as "v*" or "pkg/api/v*".
+	// The part after the literal prefix must be a semantic version. When empty, every
+	// tag that is a semantic version (with or without "v") is a release.
+	TagPatterns []string `yaml:"tag_patterns,omitempty"`
+	// SkipPrereleases ignores tags such as "v1.2.0-rc.1", so their changes are listed
+	// under the final release.
+	SkipPrereleases bool `yaml:"skip_prereleases,omitempty"`
+	// FirstParent lists each merge as one entry, titled after its pull request, with the
+	// commits it brought in nested beneath it.
+	FirstParent bool `yaml:"first_parent,omitempty"`
+	// HideMergedCommits leaves out the nested commits in first-parent mode.
+	HideMergedCommits bool `yaml:"hide_merged_commits,omitempty"`
+	// Layout organizes each release by "tags" (the default) or by "workspaces", with one
+	// section per workspace and its entries grouped by tag underneath.
+	Layout string `yaml:"layout,omitempty"`
+	// Mailmap merges the identities of contributors, like a .mailmap file: each email maps
+	// to the canonical "Name <email>", or to another email.
+	Mailmap map[string]string `yaml:"mailmap,omitempty"`
+	// Exclude leaves commits out of the release notes.
+	Exclude Exclude `yaml:"exclude,omitempty"`
+}
+
+// Release holds the settings used when computing and tagging the next version.
+type Release struct {
+	// Channel makes each release a pre-release of the next version on that channel, such
+	// as "alpha", "beta" or "rc" (v1.4.0-beta.1, v1.4.0-beta.2...). Empty tags final versions.
+	Channel string `yaml:"channel,omitempty"`
+	// Policy turns the impact of the changes into the next version: "semver" (the default),
+	// "semver0", where breaking changes only bump the minor version while it is 0.x, or
+	// "calver" for YYYY.MM.patch versions.
+	Policy string `yaml:"policy,omitempty"`
+	// ConfirmMajor keeps major releases from being tagged without an explicit confirmation.
+	ConfirmMajor bool `yaml:"confirm_major,omitempty"`
+	// VersionFiles are rewritten to the new version and committed before it is tagged.
+	VersionFiles []VersionFile `yaml:"version_files,omitempty"`
+}
+
+// VersionFile holds a file that spells out the version, and where. Exactly one of Pattern
+// and Key is set.
+type VersionFile struct {
+	// Path is the file, relative to the root of the repository.
+	Path string `yaml:"path"`
+	// Pattern is a regular expression whose first capture group is the version, e.g.
+	// `var version = "(.*)"`. Every match is rewritten.
+	Pattern string `yaml:"pattern,omitempty"`
+	// Key is the dotted path of the version in a JSON or YAML file, e.g. "version".
+	Key string `yaml:"key,omitempty"`
+}
+
+// Package holds an independently versioned part of a monorepo.
+type Package struct {
+	Name string `yaml:"name"`
+	// Path is the directory of the package, e.g. "services/api". A commit belongs to every
+	// package it touches a file of.
+	Path string `yaml:"path"`
+	// TagPattern selects the release tags of the package, e.g. "api/v*". Defaults to
+	// "<name>/v*".
+	TagPattern string `yaml:"tag_pattern,omitempty"`
+	// Changelog is the Keep a Changelog file of the package, "<path>/CHANGELOG.md" by default.
+	Changelog string `yaml:"changelog,omitempty"`
+	// VersionFiles are the version files of the package, in place of release.version_files.
+	VersionFiles []VersionFile `yaml:"version_files,omitempty"`
+}
+
+// Exclude lists the rules that keep commits out of the changelog. A commit matching any
+// rule is left out; commits can also be hidden one by one from the history view.
+type Exclude struct {
+	// Tags are semantic tags such as "chore" or "experiment".
+	Tags []string `yaml:"tags,omitempty"`
+	// Messages are regular expressions matched against the full commit message.
+	Messages []string `yaml:"messages,omitempty"`
+	// Authors are regular expressions matched against "Name <email>", e.g. `\[bot\]`.
+	Authors []string `yaml:"authors,omitempty"`
+	// Paths are globs such as "docs/**". A commit is left out when every file it touches
+	// matches one of them.
+	Paths []string `yaml:"paths,omitempty"`
+}
+
+// IssuePattern describes a ticket reference format and where it links to.
+type IssuePattern struct {
+	// Pattern is a regular expression matching the reference. The first capture group,
+	// if any, is used as the {id}; otherwise the whole match is.
+	Pattern string `yaml:"pattern"`
+	// URL is the link template, with {id} replaced by the matched ID.
+	URL string `yaml:"url,omitempty"`
+}
+
 // Manager handles loading and saving the config file.
 type Manager struct {
 	RootPath string
@@ -50,10 +153,46 @@ func (m *Manager) Load() (*Config, error) {
 	if err := yaml.Unmarshal(data, &cfg); err != nil {
 		return nil, fmt.Errorf("could not parse config: %w", err)
 	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
 
 	return &cfg, nil
 }
 
+// Validate checks the regular expressions of the config, so that a typo is reported
+// instead of silently matching nothing.
+func (c *Config) Validate() error {
+	var patterns [][2]string // setting, pattern
+	for i, p := range c.Changelog.Exclude.Messages {
+		patterns = append(patterns, [2]string{fmt.Sprintf("changelog.exclude.messages[%d]", i), p})
+	}
+	for i, p := range c.Changelog.Exclude.Authors {
+		patterns = append(patterns, [2]string{fmt.Sprintf("changelog.exclude.authors[%d]", i), p})
+	}
+	for i, p := range c.Changelog.IssuePatterns {
+		patterns = append(patterns, [2]string{fmt.Sprintf("changelog.issue_patterns[%d].pattern", i), p.Pattern})
+	}
+	for i, vf := range c.Release.VersionFiles {
+		if vf.Pattern != "" {
+			patterns = append(patterns, [2]string{fmt.Sprintf("release.version_files[%d].pattern", i), vf.Pattern})
+		}
+	}
+	for i, pkg := range c.Packages {
+		for j, vf := range pkg.VersionFiles {
+			if vf.Pattern != "" {
+				patterns = append(patterns, [2]string{fmt.Sprintf("packages[%d].version_files[%d].pattern", i, j), vf.Pattern})
+			}
+		}
+	}
+	for _, p := range patterns {
+		if _, err := regexp.Compile(p[1]); err != nil {
+			return fmt.Errorf("%s: %w", p[0], err)
+		}
+	}
+	return nil
+}
+
 // Save writes the config to disk.
 func (m *Manager) Save(cfg *Config) error {
 	path := m.configPath()
@@ -76,6 +76,86 @@ func TestManager_SaveWithSchema(t *testing.T) {
 	}
 }
 
+func TestManager_LoadChangelogSettings(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "tutugit-config-changelog-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	yml := `project:
+  name: demo
+changelog:
+  issue_url: https://tracker.example.com/issues/{id}
+  issue_patterns:
+    - pattern: 'PROJ-\d+'
+      url: https://jira.example.com/browse/{id}
+  tag_patterns: ["v*", "pkg/api/v*"]
+  skip_prereleases: true
+  first_parent: true
+  hide_merged_commits: true
+  layout: workspaces
+  mailmap:
+    jane@old-laptop.local: Jane Doe <jane@example.com>
+  exclude:
+    tags: [chore]
+    authors: ['\[bot\]']
+    paths: ["docs/**"]
+`
+	os.MkdirAll(filepath.Join(tmpDir, ".tutugit"), 0755)
+	if err := os.WriteFile(filepath.Join(tmpDir, ".tutugit", "config.yml"), []byte(yml), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := NewManager(tmpDir).Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Changelog.IssueURL != "https://tracker.example.com/issues/{id}" {
+		t.Errorf("Unexpected issue_url: %s", cfg.Changelog.IssueURL)
+	}
+	if len(cfg.Changelog.IssuePatterns) != 1 || cfg.Changelog.IssuePatterns[0].Pattern != `PROJ-\d+` {
+		t.Errorf("Unexpected issue_patterns: %+v", cfg.Changelog.IssuePatterns)
+	}
+	if len(cfg.Changelog.TagPatterns) != 2 || cfg.Changelog.TagPatterns[1] != "pkg/api/v*" || !cfg.Changelog.SkipPrereleases {
+		t.Errorf("Unexpected tag selection: %+v", cfg.Changelog)
+	}
+	if !cfg.Changelog.FirstParent || !cfg.Changelog.HideMergedCommits {
+		t.Errorf("Unexpected merge settings: %+v", cfg.Changelog)
+	}
+	if cfg.Changelog.Mailmap["jane@old-laptop.local"] != "Jane Doe <jane@example.com>" {
+		t.Errorf("Unexpected mailmap: %v", cfg.Changelog.Mailmap)
+	}
+	if cfg.Changelog.Layout != "workspaces" {
+		t.Errorf("Unexpected layout: %s", cfg.Changelog.Layout)
+	}
+	if ex := cfg.Changelog.Exclude; len(ex.Tags) != 1 || ex.Authors[0] != `\[bot\]` || ex.Paths[0] != "docs/**" {
+		t.Errorf("Unexpected exclusions: %+v", ex)
+	}
+}
+
+func TestManager_LoadInvalidPatterns(t *testing.T) {
+	tests := []struct {
+		yml, setting string
+	}{
+		{"changelog:\n  exclude:\n    messages: ['ok', '[skip']\n", "changelog.exclude.messages[1]"},
+		{"changelog:\n  exclude:\n    authors: ['(bot']\n", "changelog.exclude.authors[0]"},
+		{"changelog:\n  issue_patterns:\n    - pattern: 'PROJ-\\d+)'\n", "changelog.issue_patterns[0].pattern"},
+		{"packages:\n  - name: api\n    version_files:\n      - path: VERSION\n        pattern: '*'\n", "packages[0].version_files[0].pattern"},
+	}
+	for _, tt := range tests {
+		tmpDir := t.TempDir()
+		os.MkdirAll(filepath.Join(tmpDir, ".tutugit"), 0755)
+		if err := os.WriteFile(filepath.Join(tmpDir, ".tutugit", "config.yml"), []byte(tt.yml), 0644); err != nil {
+			t.Fatal(err)
+		}
+		_, err := NewManager(tmpDir).Load()
+		if err == nil || !contains(err.Error(), tt.setting) || !contains(err.Error(), "error parsing regexp") {
+			t.Errorf("Expected an error naming %s, got %v", tt.setting, err)
+		}
+	}
+}
+
 func contains(s, substr string) bool {
 	for i := 0; i <= len(s)-len(substr); i++ {
 		if s[i:i+len(substr)] == substr {
@@ -6,12 +6,19 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
+	"time"
 )
 
 const (
 	// Git log parsing constants
-	minLogPartsCount = 7 // Minimum number of fields in a parsed log entry
+	minLogPartsCount = 8 // Minimum number of fields in a parsed log entry
+
+	// logFormat -> hash, short hash, parents, author, email, author date, committer date,
+	// subject and body. Dates are strict ISO 8601 so they keep their timezone.
+	// %x1f = Unit Separator, %x1e = Record Separator
+	logFormat = "%H%x1f%h%x1f%P%x1f%an%x1f%ae%x1f%aI%x1f%cI%x1f%s%x1f%B%x1f%x1e"
 )
 
 // GitProvider defines the operations needed by tutugit's semantic layer.
@@ -39,6 +46,13 @@ type GitProvider interface {
 	GetCommitsInRange(ctx context.Context, base, head string) ([]Commit, error)
 	ParseStatus(ctx context.Context) ([]FileStatus, error)
 	GetTags(ctx context.Context) ([]string, error)
+	GetBranches(ctx context.Context) ([]string, error)
+	CreateTag(ctx context.Context, name, message string) error
+	GetReachableTags(ctx context.Context, rev string) ([]string, error)
+	GetTagDate(ctx context.Context, tag string) (time.Time, error)
+	GetTagRefs(ctx context.Context) ([]TagRef, error)
+	GetHistory(ctx context.Context, head string, exclude []string) ([]Commit, error)
+	GetCommitStats(ctx context.Context, hashes []string) (map[string][]FileStat, error)
 	ValidateHash(ctx context.Context, hash string) bool
 	RunInteractiveRebase(ctx context.Context, base string, steps []RebaseStep) error
 }
@@ -181,17 +195,17 @@ type Commit struct {
 	Parents   []string
 	Author    string
 	Email     string
-	Date      string
-	Message   string
-	Body      string
+	// AuthorDate is when the change was written, CommitterDate when it was last applied
+	// (they differ after a rebase or cherry-pick). Both keep the original timezone.
+	AuthorDate    time.Time
+	CommitterDate time.Time
+	Message       string
+	Body          string
 }
 
 // GetLog -> returns the commit history.
 func (r *Runner) GetLog(ctx context.Context, n int) ([]Commit, error) {
-	// %x1f = Unit Separator
-	// %x1e = Record Separator
-	format := "%H%x1f%h%x1f%P%x1f%an%x1f%ae%x1f%cr%x1f%s%x1f%B%x1f%x1e"
-	args := []string{"log", fmt.Sprintf("-n%d", n), "--pretty=format:" + format}
+	args := []string{"log", fmt.Sprintf("-n%d", n), "--pretty=format:" + logFormat}
 	return r.parseLog(ctx, args)
 }
 
@@ -228,11 +242,13 @@ func (r *Runner) parseLog(ctx context.Context, args []string) ([]Commit, error)
 			Parents:   parents,
 			Author:    parts[3],
 			Email:     parts[4],
-			Date:      parts[5],
-			Message:   parts[6],
+			Message:   parts[7],
 		}
-		if len(parts) > 7 {
-			c.Body = parts[7]
+		// unparsable dates are left zero rather than dropping the commit
+		c.AuthorDate, _ = time.Parse(time.RFC3339, parts[5])
+		c.CommitterDate, _ = time.Parse(time.RFC3339, parts[6])
+		if len(parts) > 8 {
+			c.Body = parts[8]
 		}
 		commits = append(commits, c)
 	}
@@ -438,12 +454,11 @@ func (r *Runner) RebaseSkip(ctx context.Context) error {
 
 // GetCommitsInRange returns commits between base and head (excluding base).
 func (r *Runner) GetCommitsInRange(ctx context.Context, base, head string) ([]Commit, error) {
-	format := "%H%x1f%h%x1f%P%x1f%an%x1f%ae%x1f%cr%x1f%s%x1f%B%x1f%x1e"
 	rangeSpec := fmt.Sprintf("%s..%s", base, head)
 	if base == "" {
 		rangeSpec = head
 	}
-	args := []string{"log", "--pretty=format:" + format, "--reverse", rangeSpec}
+	args := []string{"log", "--pretty=format:" + logFormat, "--reverse", rangeSpec}
 	return r.parseLog(ctx, args)
 }
 
@@ -561,6 +576,151 @@ func (r *Runner) GetTags(ctx context.Context) ([]string, error) {
 	return strings.Split(strings.TrimSpace(output), "\n"), nil
 }
 
+// GetBranches -> returns the local branches, most recently committed first.
+func (r *Runner) GetBranches(ctx context.Context) ([]string, error) {
+	output, err := r.Run(ctx, "for-each-ref", "--sort=-committerdate", "--format=%(refname:short)", "refs/heads")
+	if err != nil {
+		return nil, fmt.Errorf("could not list branches: %w", err)
+	}
+	if output == "" {
+		return nil, nil
+	}
+	return strings.Split(strings.TrimSpace(output), "\n"), nil
+}
+
+// CreateTag -> creates an annotated tag on HEAD.
+func (r *Runner) CreateTag(ctx context.Context, name, message string) error {
+	if _, err := r.Run(ctx, "tag", "-a", name, "-m", message); err != nil {
+		return fmt.Errorf("could not create tag %s: %w", name, err)
+	}
+	return nil
+}
+
+// TagRef -> a tag with the commit it points to and its creation date.
+type TagRef struct {
+	Name string
+	Hash string    // the tagged commit (annotated tags are peeled)
+	Date time.Time // tagger date for annotated tags, committer date for lightweight ones
+}
+
+// GetTagRefs -> returns every tag with its commit and date in a single call.
+func (r *Runner) GetTagRefs(ctx context.Context) ([]TagRef, error) {
+	format := "--format=%(refname:short)%1f%(objectname)%1f%(*objectname)%1f%(creatordate:iso-strict)"
+	output, err := r.Run(ctx, "for-each-ref", format, "refs/tags")
+	if err != nil {
+		return nil, fmt.Errorf("could not list tag refs: %w", err)
+	}
+
+	var refs []TagRef
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.Split(strings.TrimSpace(line), "\x1f")
+		if len(parts) < 4 || parts[0] == "" {
+			continue
+		}
+		ref := TagRef{Name: parts[0], Hash: parts[1]}
+		if parts[2] != "" {
+			ref.Hash = parts[2]
+		}
+		ref.Date, _ = time.Parse(time.RFC3339, parts[3])
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
+
+// GetHistory -> returns the commits reachable from head but not from any of the excluded
+// revisions, newest first in topological order (children always before their parents).
+func (r *Runner) GetHistory(ctx context.Context, head string, exclude []string) ([]Commit, error) {
+	args := []string{"log", "--topo-order", "--pretty=format:" + logFormat, head}
+	if len(exclude) > 0 {
+		args = append(args, "--not")
+		args = append(args, exclude...)
+	}
+	return r.parseLog(ctx, args)
+}
+
+// FileStat -> the lines a commit added and deleted in a file.
+type FileStat struct {
+	Path      string
+	Additions int
+	Deletions int
+	Binary    bool // binary files have no line counts
+}
+
+// GetCommitStats -> returns the files touched by each commit with their line counts, in a
+// single call. Merges are compared with their first parent, so they count everything the
+// merged branch changed.
+func (r *Runner) GetCommitStats(ctx context.Context, hashes []string) (map[string][]FileStat, error) {
+	stats := make(map[string][]FileStat, len(hashes))
+	if len(hashes) == 0 {
+		return stats, nil
+	}
+
+	args := []string{"diff-tree", "--stdin", "-r", "--numstat", "--no-renames", "--root", "--diff-merges=first-parent"}
+	cmd := r.gitCommand(ctx, args...)
+	cmd.Stdin = strings.NewReader(strings.Join(hashes, "\n") + "\n")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("could not get commit stats: %w\nstderr: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	// each commit hash is followed by "added<TAB>deleted<TAB>path" lines
+	current := ""
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		parts := strings.SplitN(line, "\t", 3)
+		if len(parts) != 3 {
+			if line = strings.TrimSpace(line); line != "" {
+				current = line
+				stats[current] = []FileStat{}
+			}
+			continue
+		}
+		if current == "" {
+			continue
+		}
+		fs := FileStat{Path: parts[2]}
+		if parts[0] == "-" && parts[1] == "-" {
+			fs.Binary = true
+		} else {
+			fs.Additions, _ = strconv.Atoi(parts[0])
+			fs.Deletions, _ = strconv.Atoi(parts[1])
+		}
+		stats[current] = append(stats[current], fs)
+	}
+	return stats, nil
+}
+
+// GetReachableTags -> returns the tags whose commit is an ancestor of rev (or rev itself).
+func (r *Runner) GetReachableTags(ctx context.Context, rev string) ([]string, error) {
+	output, err := r.Run(ctx, "tag", "-l", "--merged", rev)
+	if err != nil {
+		return nil, fmt.Errorf("could not list tags reachable from %s: %w", rev, err)
+	}
+	if output == "" {
+		return nil, nil
+	}
+	return strings.Split(strings.TrimSpace(output), "\n"), nil
+}
+
+// GetTagDate -> returns when a tag was created: the tagger date of an annotated tag,
+// or the committer date of the tagged commit for a lightweight one.
+func (r *Runner) GetTagDate(ctx context.Context, tag string) (time.Time, error) {
+	output, err := r.Run(ctx, "for-each-ref", "--format=%(creatordate:iso-strict)", "refs/tags/"+tag)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("could not get date of tag %s: %w", tag, err)
+	}
+	output = strings.TrimSpace(output)
+	if output == "" {
+		return time.Time{}, fmt.Errorf("tag %s not found", tag)
+	}
+	date, err := time.Parse(time.RFC3339, output)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("could not parse date of tag %s: %w", tag, err)
+	}
+	return date, nil
+}
+
 // ValidateHash -> checks if a commit hash exists and is reachable from any branch.
 func (r *Runner) ValidateHash(ctx context.Context, hash string) bool {
 	output, err := r.Run(ctx, "branch", "-a", "--contains", hash)
@@ -6,6 +6,8 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
 	"testing"
 	"time"
@@ -335,6 +337,41 @@ func TestRunner_GetLog(t *testing.T) {
 	if commits[0].Email != "test@example.com" {
 		t.Errorf("Unexpected email: %s", commits[0].Email)
 	}
+	if commits[0].AuthorDate.IsZero() || commits[0].CommitterDate.IsZero() {
+		t.Error("Author and committer dates should be parsed")
+	}
+}
+
+func TestRunner_GetLog_DatesKeepTimezone(t *testing.T) {
+	dir, cleanup := setupGitRepo(t)
+	defer cleanup()
+
+	r := NewRunner(dir)
+	ctx := context.Background()
+
+	os.WriteFile(filepath.Join(dir, "test.txt"), []byte("data"), 0644)
+	r.StageFile(ctx, "test.txt")
+	cmd := exec.Command("git", "commit", "-m", "dated")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_DATE=2024-03-10T23:15:00-03:00",
+		"GIT_COMMITTER_DATE=2024-03-11T09:00:00+02:00",
+	)
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("commit failed: %v", err)
+	}
+
+	commits, err := r.GetLog(ctx, 1)
+	if err != nil || len(commits) != 1 {
+		t.Fatalf("GetLog failed: %v", err)
+	}
+	author := commits[0].AuthorDate
+	if got := author.Format(time.RFC3339); got != "2024-03-10T23:15:00-03:00" {
+		t.Errorf("Unexpected author date: %s", got)
+	}
+	if got := commits[0].CommitterDate.Format(time.RFC3339); got != "2024-03-11T09:00:00+02:00" {
+		t.Errorf("Unexpected committer date: %s", got)
+	}
 }
 
 func TestRunner_GetCommitsInRange(t *testing.T) {
@@ -406,6 +443,199 @@ func TestRunner_GetTags(t *testing.T) {
 	}
 }
 
+func TestRunner_GetBranches(t *testing.T) {
+	dir, cleanup := setupGitRepo(t)
+	defer cleanup()
+
+	r := NewRunner(dir)
+	ctx := context.Background()
+
+	os.WriteFile(filepath.Join(dir, "test.txt"), []byte("data"), 0644)
+	r.StageFile(ctx, "test.txt")
+	r.Commit(ctx, "initial")
+	current, _ := r.GetCurrentBranch(ctx)
+	r.Run(ctx, "branch", "feature/login")
+
+	branches, err := r.GetBranches(ctx)
+	if err != nil {
+		t.Fatalf("GetBranches failed: %v", err)
+	}
+	sort.Strings(branches)
+	want := []string{"feature/login", current}
+	sort.Strings(want)
+	if !reflect.DeepEqual(branches, want) {
+		t.Errorf("Expected %v, got %v", want, branches)
+	}
+}
+
+func TestRunner_CreateTag(t *testing.T) {
+	dir, cleanup := setupGitRepo(t)
+	defer cleanup()
+
+	r := NewRunner(dir)
+	ctx := context.Background()
+
+	os.WriteFile(filepath.Join(dir, "test.txt"), []byte("data"), 0644)
+	r.StageFile(ctx, "test.txt")
+	r.Commit(ctx, "initial")
+
+	if err := r.CreateTag(ctx, "v1.0.0-beta.1", "Release v1.0.0-beta.1"); err != nil {
+		t.Fatalf("CreateTag failed: %v", err)
+	}
+	if tp, _ := r.Run(ctx, "cat-file", "-t", "v1.0.0-beta.1"); strings.TrimSpace(tp) != "tag" {
+		t.Errorf("Expected an annotated tag, got %q", tp)
+	}
+	if err := r.CreateTag(ctx, "v1.0.0-beta.1", "again"); err == nil {
+		t.Error("Expected an error for an existing tag")
+	}
+}
+
+func TestRunner_GetReachableTags(t *testing.T) {
+	dir, cleanup := setupGitRepo(t)
+	defer cleanup()
+
+	r := NewRunner(dir)
+	ctx := context.Background()
+
+	os.WriteFile(filepath.Join(dir, "test.txt"), []byte("data"), 0644)
+	r.StageFile(ctx, "test.txt")
+	r.Commit(ctx, "initial")
+	r.Run(ctx, "tag", "v1.0.0")
+
+	// a tag on a side branch is not part of HEAD's history
+	r.Run(ctx, "checkout", "-b", "side")
+	os.WriteFile(filepath.Join(dir, "side.txt"), []byte("data"), 0644)
+	r.StageFile(ctx, "side.txt")
+	r.Commit(ctx, "side work")
+	r.Run(ctx, "tag", "v2.0.0")
+	r.Run(ctx, "checkout", "-")
+
+	tags, err := r.GetReachableTags(ctx, "HEAD")
+	if err != nil {
+		t.Fatalf("GetReachableTags failed: %v", err)
+	}
+	if len(tags) != 1 || tags[0] != "v1.0.0" {
+		t.Errorf("Expected only v1.0.0, got %v", tags)
+	}
+}
+
+func TestRunner_GetTagRefsAndHistory(t *testing.T) {
+	dir, cleanup := setupGitRepo(t)
+	defer cleanup()
+
+	r := NewRunner(dir)
+	ctx := context.Background()
+
+	for i := 1; i <= 3; i++ {
+		name := fmt.Sprintf("file%d.txt", i)
+		os.WriteFile(filepath.Join(dir, name), []byte("data"), 0644)
+		r.StageFile(ctx, name)
+		r.Commit(ctx, fmt.Sprintf("commit %d", i))
+		if i == 1 {
+			r.Run(ctx, "tag", "v1.0.0")
+		}
+		if i == 2 {
+			r.Run(ctx, "tag", "-a", "v1.1.0", "-m", "annotated")
+		}
+	}
+	commits, _ := r.GetLog(ctx, 10)
+
+	refs, err := r.GetTagRefs(ctx)
+	if err != nil {
+		t.Fatalf("GetTagRefs failed: %v", err)
+	}
+	byName := make(map[string]TagRef)
+	for _, ref := range refs {
+		byName[ref.Name] = ref
+	}
+	if byName["v1.0.0"].Hash != commits[2].Hash {
+		t.Errorf("lightweight tag should point at commit 1, got %s", byName["v1.0.0"].Hash)
+	}
+	if byName["v1.1.0"].Hash != commits[1].Hash {
+		t.Errorf("annotated tag should be peeled to commit 2, got %s", byName["v1.1.0"].Hash)
+	}
+	if byName["v1.1.0"].Date.IsZero() {
+		t.Error("tag date should be parsed")
+	}
+
+	history, err := r.GetHistory(ctx, "HEAD", []string{"v1.0.0"})
+	if err != nil {
+		t.Fatalf("GetHistory failed: %v", err)
+	}
+	if len(history) != 2 || history[0].Message != "commit 3" || history[1].Message != "commit 2" {
+		t.Errorf("Expected commits 3 and 2 newest first, got %+v", history)
+	}
+}
+
+func TestRunner_GetCommitStats(t *testing.T) {
+	dir, cleanup := setupGitRepo(t)
+	defer cleanup()
+
+	r := NewRunner(dir)
+	ctx := context.Background()
+
+	os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644)
+	r.StageFile(ctx, "main.go")
+	r.Commit(ctx, "root")
+
+	os.MkdirAll(filepath.Join(dir, "docs"), 0755)
+	os.WriteFile(filepath.Join(dir, "docs", "a.md"), []byte("a\nb\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "docs", "logo.png"), []byte{0x89, 'P', 'N', 'G', 0, 0, 1}, 0644)
+	os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0644)
+	r.StageFile(ctx, "docs")
+	r.StageFile(ctx, "main.go")
+	r.Commit(ctx, "docs")
+
+	commits, _ := r.GetLog(ctx, 2)
+	stats, err := r.GetCommitStats(ctx, []string{commits[0].Hash, commits[1].Hash})
+	if err != nil {
+		t.Fatalf("GetCommitStats failed: %v", err)
+	}
+	want := []FileStat{
+		{Path: "docs/a.md", Additions: 2},
+		{Path: "docs/logo.png", Binary: true},
+		{Path: "main.go", Deletions: 2},
+	}
+	if got := stats[commits[0].Hash]; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Unexpected stats of the docs commit: %+v", got)
+	}
+	if got := stats[commits[1].Hash]; len(got) != 1 || got[0] != (FileStat{Path: "main.go", Additions: 3}) {
+		t.Errorf("Root commit should list its files, got %+v", got)
+	}
+}
+
+func TestRunner_GetTagDate(t *testing.T) {
+	dir, cleanup := setupGitRepo(t)
+	defer cleanup()
+
+	r := NewRunner(dir)
+	ctx := context.Background()
+
+	os.WriteFile(filepath.Join(dir, "test.txt"), []byte("data"), 0644)
+	r.StageFile(ctx, "test.txt")
+	r.Commit(ctx, "initial")
+
+	// annotated tags are dated by the tagger, not by the commit
+	cmd := exec.Command("git", "tag", "-a", "v1.0.0", "-m", "release")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_COMMITTER_DATE=2024-05-01T12:00:00+05:30")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("tag failed: %v", err)
+	}
+
+	date, err := r.GetTagDate(ctx, "v1.0.0")
+	if err != nil {
+		t.Fatalf("GetTagDate failed: %v", err)
+	}
+	if got := date.Format(time.RFC3339); got != "2024-05-01T12:00:00+05:30" {
+		t.Errorf("Unexpected tag date: %s", got)
+	}
+
+	if _, err := r.GetTagDate(ctx, "v9.9.9"); err == nil {
+		t.Error("Expected error for a missing tag")
+	}
+}
+
 func TestRunner_ValidateHash(t *testing.T) {
 	dir, cleanup := setupGitRepo(t)
 	defer cleanup()
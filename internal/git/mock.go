@@ -3,6 +3,7 @@ package git
 import (
 	"context"
 	"fmt"
+	"time"
 )
 
 // MockRunner is a mock implementation of GitProvider for testing.
@@ -14,6 +15,11 @@ type MockRunner struct {
 	Reflog        []ReflogEntry
 	Worktrees     []Worktree
 	Tags          []string
+	Branches      []string
+	TagDates      map[string]time.Time
+	Unreachable   map[string]bool   // tags GetReachableTags leaves out
+	TagCommits    map[string]string // tag -> tagged commit hash
+	CommitStats   map[string][]FileStat // commit hash -> touched files
 	RemoteURL     string
 	IsRebasingVal bool
 	RebaseTodo    []RebaseStep
@@ -69,12 +75,13 @@ func (m *MockRunner) Commit(ctx context.Context, message string) error {
 	// Create a new commit
 	hashStr := fmt.Sprintf("mock%d", len(m.Commits)+1)
 	newCommit := Commit{
-		Hash:      hashStr,
-		ShortHash: hashStr[:4], // "mock" is 4 chars, so this is safe
-		Message:   message,
-		Author:    "Demo User",
-		Date:      "Just now",
-		Email:     "demo@tutugit.local",
+		Hash:          hashStr,
+		ShortHash:     hashStr[:4], // "mock" is 4 chars, so this is safe
+		Message:       message,
+		Author:        "Demo User",
+		AuthorDate:    time.Now(),
+		CommitterDate: time.Now(),
+		Email:         "demo@tutugit.local",
 	}
 	
 	// Add to beginning of commits slice (HEAD)
@@ -226,6 +233,83 @@ func (m *MockRunner) GetTags(ctx context.Context) ([]string, error) {
 	return m.Tags, nil
 }
 
+func (m *MockRunner) GetBranches(ctx context.Context) ([]string, error) {
+	return m.Branches, nil
+}
+
+// CreateTag tags the newest commit, dated now.
+func (m *MockRunner) CreateTag(ctx context.Context, name, message string) error {
+	for _, t := range m.Tags {
+		if t == name {
+			return fmt.Errorf("tag %s already exists", name)
+		}
+	}
+	if m.TagCommits == nil {
+		m.TagCommits = make(map[string]string)
+	}
+	if m.TagDates == nil {
+		m.TagDates = make(map[string]time.Time)
+	}
+	if len(m.Commits) > 0 {
+		m.TagCommits[name] = m.Commits[0].Hash
+	}
+	m.TagDates[name] = time.Now()
+	m.Tags = append([]string{name}, m.Tags...)
+	return nil
+}
+
+func (m *MockRunner) GetReachableTags(ctx context.Context, rev string) ([]string, error) {
+	var tags []string
+	for _, t := range m.Tags {
+		if !m.Unreachable[t] {
+			tags = append(tags, t)
+		}
+	}
+	return tags, nil
+}
+
+func (m *MockRunner) GetTagRefs(ctx context.Context) ([]TagRef, error) {
+	var refs []TagRef
+	for _, t := range m.Tags {
+		refs = append(refs, TagRef{Name: t, Hash: m.TagCommits[t], Date: m.TagDates[t]})
+	}
+	return refs, nil
+}
+
+// GetHistory returns Commits as a linear history, stopping at the first excluded tag's commit.
+func (m *MockRunner) GetHistory(ctx context.Context, head string, exclude []string) ([]Commit, error) {
+	stop := make(map[string]bool)
+	for _, ex := range exclude {
+		if hash, ok := m.TagCommits[ex]; ok {
+			stop[hash] = true
+		}
+		stop[ex] = true
+	}
+	var commits []Commit
+	for _, c := range m.Commits {
+		if stop[c.Hash] {
+			break
+		}
+		commits = append(commits, c)
+	}
+	return commits, nil
+}
+
+func (m *MockRunner) GetCommitStats(ctx context.Context, hashes []string) (map[string][]FileStat, error) {
+	stats := make(map[string][]FileStat, len(hashes))
+	for _, h := range hashes {
+		stats[h] = m.CommitStats[h]
+	}
+	return stats, nil
+}
+
+func (m *MockRunner) GetTagDate(ctx context.Context, tag string) (time.Time, error) {
+	if date, ok := m.TagDates[tag]; ok {
+		return date, nil
+	}
+	return time.Time{}, fmt.Errorf("tag %s not found", tag)
+}
+
 func (m *MockRunner) ValidateHash(ctx context.Context, hash string) bool {
 	return m.ValidHashes[hash]
 }
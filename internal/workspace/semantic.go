@@ -8,6 +8,21 @@ import (
 // commitPrefixRegex matches conventional commit prefixes like "feat:", "fix(scope):", "feat!:", etc.
 var commitPrefixRegex = regexp.MustCompile(`(?i)^(feat|feature|fix|bugfix|refactor|experiment|exp)(\([^)]*\))?(!)?:\s*`)
 
+// scopeRegex matches the scope of any conventional commit header, e.g. "docs(readme):" or "feat(api)!:".
+var scopeRegex = regexp.MustCompile(`^[A-Za-z]+\(([^)]*)\)!?:`)
+
+// breakingHeaderRegex matches a conventional commit header flagged as breaking, e.g. "feat!:" or "chore(deps)!:".
+var breakingHeaderRegex = regexp.MustCompile(`^[A-Za-z]+(\([^)]*\))?!:\s*`)
+
+// breakingFooterRegex matches the "BREAKING CHANGE:" (or "BREAKING-CHANGE:") footer of a commit message.
+var breakingFooterRegex = regexp.MustCompile(`(?m)^BREAKING[ -]CHANGE:\s*`)
+
+// revertSubjectRegex matches git's default revert subject (Revert "...") and the conventional "revert:" prefix.
+var revertSubjectRegex = regexp.MustCompile(`(?i)^(?:revert\s+"(.*)"|revert(?:\([^)]*\))?!?:\s*(.*))$`)
+
+// revertBodyRegex matches the "This reverts commit <sha>" line written by git revert.
+var revertBodyRegex = regexp.MustCompile(`(?i)This reverts commit ([0-9a-f]{4,40})`)
+
 // prefixToTag maps recognized prefixes to their semantic tag.
 var prefixToTag = map[string]string{
 	"feat":       "feature",
@@ -34,6 +49,62 @@ func DetectTag(message string) string {
 	return "none"
 }
 
+// DetectScope returns the conventional commit scope of a message, e.g. "api" for
+// "feat(api): add endpoint". Returns an empty string if the header has no scope.
+func DetectScope(message string) string {
+	msg := strings.TrimSpace(message)
+	match := scopeRegex.FindStringSubmatch(msg)
+	if len(match) < 2 {
+		return ""
+	}
+	return strings.TrimSpace(match[1])
+}
+
+// DetectBreaking returns the description of a breaking change declared in the message.
+// The text of the "BREAKING CHANGE:" footer (up to the next blank line) wins; if the change is only
+// flagged with "!" in the header, the header description is returned. Returns an empty string
+// when the message declares no breaking change.
+func DetectBreaking(message string) string {
+	msg := strings.TrimSpace(message)
+
+	if loc := breakingFooterRegex.FindStringIndex(msg); loc != nil {
+		rest := msg[loc[1]:]
+		if end := strings.Index(rest, "\n\n"); end >= 0 {
+			rest = rest[:end]
+		}
+		if desc := strings.Join(strings.Fields(rest), " "); desc != "" {
+			return desc
+		}
+	}
+
+	header := strings.Split(msg, "\n")[0]
+	if loc := breakingHeaderRegex.FindStringIndex(header); loc != nil {
+		return strings.TrimSpace(header[loc[1]:])
+	}
+	return ""
+}
+
+// DetectRevert reports whether a message reverts another commit. It returns the reverted
+// commit SHA from the "This reverts commit <sha>" line (empty if absent) and the subject of
+// the reverted commit as quoted in the header (empty if absent).
+func DetectRevert(message string) (isRevert bool, sha, subject string) {
+	msg := strings.TrimSpace(message)
+	header := strings.TrimSpace(strings.Split(msg, "\n")[0])
+
+	if match := revertSubjectRegex.FindStringSubmatch(header); match != nil {
+		isRevert = true
+		subject = match[1]
+		if subject == "" {
+			subject = match[2]
+		}
+	}
+	if match := revertBodyRegex.FindStringSubmatch(msg); match != nil {
+		isRevert = true
+		sha = strings.ToLower(match[1])
+	}
+	return isRevert, sha, strings.TrimSpace(subject)
+}
+
 // DetectImpact suggests the impact level (patch, minor, major) based on the commit message.
 // - major: if it contains a breaking change indicator (!) in the prefix or 'BREAKING CHANGE' in body.
 // - minor: if it's a 'feat' or 'feature'.
@@ -26,6 +26,71 @@ func TestDetectTag(t *testing.T) {
 	}
 }
 
+func TestDetectScope(t *testing.T) {
+	tests := []struct {
+		message  string
+		expected string
+	}{
+		{"feat(api): add endpoint", "api"},
+		{"fix(ui)!: breaking fix", "ui"},
+		{"docs(readme): typo", "readme"},
+		{"feat( core ): spaced scope", "core"},
+		{"feat: no scope", ""},
+		{"random (message): not a header", ""},
+	}
+
+	for _, tt := range tests {
+		got := DetectScope(tt.message)
+		if got != tt.expected {
+			t.Errorf("DetectScope(%q) = %q; want %q", tt.message, got, tt.expected)
+		}
+	}
+}
+
+func TestDetectBreaking(t *testing.T) {
+	tests := []struct {
+		message  string
+		expected string
+	}{
+		{"feat!: drop legacy config", "drop legacy config"},
+		{"chore(deps)!: bump go to 1.24", "bump go to 1.24"},
+		{"feat: new api\n\nBREAKING CHANGE: the v1 endpoints\nare gone", "the v1 endpoints are gone"},
+		{"feat!: header\n\nBREAKING-CHANGE: footer wins\n\nRefs: #1", "footer wins"},
+		{"fix: not breaking", ""},
+		{"random message", ""},
+	}
+
+	for _, tt := range tests {
+		got := DetectBreaking(tt.message)
+		if got != tt.expected {
+			t.Errorf("DetectBreaking(%q) = %q; want %q", tt.message, got, tt.expected)
+		}
+	}
+}
+
+func TestDetectRevert(t *testing.T) {
+	tests := []struct {
+		message  string
+		isRevert bool
+		sha      string
+		subject  string
+	}{
+		{"Revert \"feat: add login\"\n\nThis reverts commit ABC1234def.", true, "abc1234def", "feat: add login"},
+		{"revert: feat: add login", true, "", "feat: add login"},
+		{"revert(auth): drop sessions\n\nThis reverts commit 0123abcd.", true, "0123abcd", "drop sessions"},
+		{"feat: revert button for forms", false, "", ""},
+		{"fix: something", false, "", ""},
+	}
+
+	for _, tt := range tests {
+		isRevert, sha, subject := DetectRevert(tt.message)
+		if isRevert != tt.isRevert || sha != tt.sha || subject != tt.subject {
+			t.Errorf("DetectRevert(%q) = (%v, %q, %q); want (%v, %q, %q)",
+				tt.message, isRevert, sha, subject, tt.isRevert, tt.sha, tt.subject)
+		}
+	}
+}
+
 func TestDetectImpact(t *testing.T) {
 	tests := []struct {
 		message  string
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"time"
 
 	"tutugit/internal/assets"
 )
@@ -23,9 +25,40 @@ type Meta struct {
 	Schema          string              `json:"$schema,omitempty"`
 	Version         int                 `json:"version"`
 	Workspaces      []Workspace         `json:"workspaces"`
-	Tags            map[string][]string `json:"tags"`             // Commit SHA -> Tags
-	ActiveWorkspace string              `json:"active_workspace"` // ID of the currently active workspace
-	Impacts         map[string]string   `json:"impacts"`          // Commit SHA -> Impact Level (patch/minor/major)
+	Tags            map[string][]string `json:"tags"`                   // Commit SHA -> Tags
+	ActiveWorkspace string              `json:"active_workspace"`       // ID of the currently active workspace
+	Impacts         map[string]string   `json:"impacts"`                // Commit SHA -> Impact Level (patch/minor/major)
+	Scopes          map[string]string   `json:"scopes,omitempty"`       // Commit SHA -> Conventional commit scope
+	Breaking        map[string]string   `json:"breaking,omitempty"`     // Commit SHA -> Breaking change migration notes
+	Hidden          map[string]bool     `json:"hidden,omitempty"`       // Commit SHA -> left out of the changelog
+	Subjects        map[string]string   `json:"subjects,omitempty"`     // Commit SHA -> Subject shown in the changelog
+	Descriptions    map[string]string   `json:"descriptions,omitempty"` // Commit SHA -> Longer changelog description
+	Releases        []RecordedRelease   `json:"releases,omitempty"`     // Published releases, newest first
+	Upcoming        *ReleaseNotes       `json:"upcoming,omitempty"`     // Hand-written notes of the next release
+}
+
+// ReleaseNotes -> the text written by hand for a release, shown above its generated entries.
+type ReleaseNotes struct {
+	Intro      string   `json:"intro,omitempty"`
+	Highlights []string `json:"highlights,omitempty"`
+}
+
+// IsEmpty -> reports whether nothing was written.
+func (n ReleaseNotes) IsEmpty() bool {
+	return n.Intro == "" && len(n.Highlights) == 0
+}
+
+// RecordedRelease -> a release frozen at release time, so its notes stay the same when tags
+// move, history is rewritten or the metadata of its commits is edited later.
+type RecordedRelease struct {
+	Version    string          `json:"version"` // e.g. "1.2.0"
+	Date       time.Time       `json:"date"`
+	Tag        string          `json:"tag"`     // e.g. "v1.2.0"
+	Commits    []string        `json:"commits"` // SHAs of the commits listed in the notes
+	Impact     string          `json:"impact"`
+	Intro      string          `json:"intro,omitempty"`
+	Highlights []string        `json:"highlights,omitempty"`
+	Notes      json.RawMessage `json:"notes,omitempty"` // the release as generated, in the format of the JSON export
 }
 
 // Manager -> handles the persistence of Tutugit metadata.
@@ -74,6 +107,7 @@ func (m *Manager) Bootstrap() error {
 		Tags:            make(map[string][]string),
 		ActiveWorkspace: "general",
 		Impacts:         make(map[string]string),
+		Scopes:          make(map[string]string),
 	}
 
 	return m.Save(meta)
@@ -138,6 +172,12 @@ func (m *Manager) Load() (*Meta, error) {
 	if meta.Impacts == nil {
 		meta.Impacts = make(map[string]string)
 	}
+	if meta.Scopes == nil {
+		meta.Scopes = make(map[string]string)
+	}
+	if meta.Breaking == nil {
+		meta.Breaking = make(map[string]string)
+	}
 	if meta.Version == 0 {
 		meta.Version = 1
 	}
@@ -193,6 +233,25 @@ func (m *Manager) AddCommitToWorkspace(workspaceID, commitSHA string) error {
 	return m.Save(meta)
 }
 
+// RecordRelease -> stores a published release, replacing an earlier record of the same tag.
+// Records are kept newest first.
+func (m *Manager) RecordRelease(rec RecordedRelease) error {
+	meta, err := m.Load()
+	if err != nil {
+		return err
+	}
+
+	releases := []RecordedRelease{rec}
+	for _, r := range meta.Releases {
+		if r.Tag != rec.Tag {
+			releases = append(releases, r)
+		}
+	}
+	sort.SliceStable(releases, func(i, j int) bool { return releases[i].Date.After(releases[j].Date) })
+	meta.Releases = releases
+	return m.Save(meta)
+}
+
 // AddTag -> associates a tag with a commit SHA.
 func (m *Manager) AddTag(commitSHA, tag string) error {
 	meta, err := m.Load()
@@ -230,6 +289,96 @@ func (m *Manager) AddImpact(commitSHA, level string) error {
 	return m.Save(meta)
 }
 
+// AddScope -> associates a conventional commit scope with a commit SHA.
+func (m *Manager) AddScope(commitSHA, scope string) error {
+	meta, err := m.Load()
+	if err != nil {
+		return err
+	}
+
+	if meta.Scopes == nil {
+		meta.Scopes = make(map[string]string)
+	}
+
+	meta.Scopes[commitSHA] = scope
+	return m.Save(meta)
+}
+
+// AddBreakingNote -> stores the migration notes of a breaking change commit.
+func (m *Manager) AddBreakingNote(commitSHA, note string) error {
+	meta, err := m.Load()
+	if err != nil {
+		return err
+	}
+
+	if meta.Breaking == nil {
+		meta.Breaking = make(map[string]string)
+	}
+
+	meta.Breaking[commitSHA] = note
+	return m.Save(meta)
+}
+
+// SetHidden -> hides a commit from the changelog, or shows it again.
+func (m *Manager) SetHidden(commitSHA string, hidden bool) error {
+	meta, err := m.Load()
+	if err != nil {
+		return err
+	}
+
+	if hidden {
+		if meta.Hidden == nil {
+			meta.Hidden = make(map[string]bool)
+		}
+		meta.Hidden[commitSHA] = true
+	} else {
+		delete(meta.Hidden, commitSHA)
+	}
+	return m.Save(meta)
+}
+
+// SetChangelogText -> overrides the subject and description a commit has in the changelog,
+// without rewriting it. Empty values fall back to the commit message.
+func (m *Manager) SetChangelogText(commitSHA, subject, description string) error {
+	meta, err := m.Load()
+	if err != nil {
+		return err
+	}
+
+	if meta.Subjects == nil {
+		meta.Subjects = make(map[string]string)
+	}
+	if meta.Descriptions == nil {
+		meta.Descriptions = make(map[string]string)
+	}
+	setOrDelete(meta.Subjects, commitSHA, subject)
+	setOrDelete(meta.Descriptions, commitSHA, description)
+	return m.Save(meta)
+}
+
+// SetUpcomingNotes -> stores the intro and highlights of the next release. Empty notes
+// remove them.
+func (m *Manager) SetUpcomingNotes(notes ReleaseNotes) error {
+	meta, err := m.Load()
+	if err != nil {
+		return err
+	}
+
+	meta.Upcoming = nil
+	if !notes.IsEmpty() {
+		meta.Upcoming = &notes
+	}
+	return m.Save(meta)
+}
+
+func setOrDelete(values map[string]string, key, value string) {
+	if value == "" {
+		delete(values, key)
+		return
+	}
+	values[key] = value
+}
+
 // CreateWorkspace -> logical workspace.
 func (m *Manager) CreateWorkspace(id, name, desc string) error {
 	meta, err := m.Load()
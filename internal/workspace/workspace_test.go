@@ -1,9 +1,11 @@
 package workspace
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestManager_Bootstrap(t *testing.T) {
@@ -62,6 +64,26 @@ func TestManager_MetadataOperations(t *testing.T) {
 		t.Errorf("Impact not saved correctly, got %s", meta.Impacts[hash])
 	}
 
+	// Test AddScope
+	if err := m.AddScope(hash, "api"); err != nil {
+		t.Fatalf("AddScope failed: %v", err)
+	}
+
+	meta, _ = m.Load()
+	if meta.Scopes[hash] != "api" {
+		t.Errorf("Scope not saved correctly, got %s", meta.Scopes[hash])
+	}
+
+	// Test AddBreakingNote
+	if err := m.AddBreakingNote(hash, "rename config keys"); err != nil {
+		t.Fatalf("AddBreakingNote failed: %v", err)
+	}
+
+	meta, _ = m.Load()
+	if meta.Breaking[hash] != "rename config keys" {
+		t.Errorf("Breaking note not saved correctly, got %s", meta.Breaking[hash])
+	}
+
 	// Test CreateWorkspace
 	if err := m.CreateWorkspace("test-ws", "Test WS", "A test workspace"); err != nil {
 		t.Fatalf("CreateWorkspace failed: %v", err)
@@ -180,6 +202,121 @@ func TestManager_AddTag(t *testing.T) {
 	}
 }
 
+func TestManager_SetHidden(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "tutugit-test-hidden-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	m := NewManager(tmpDir)
+	m.Bootstrap()
+
+	if err := m.SetHidden("abc123", true); err != nil {
+		t.Fatalf("SetHidden failed: %v", err)
+	}
+	meta, _ := m.Load()
+	if !meta.Hidden["abc123"] {
+		t.Error("Commit should be hidden")
+	}
+
+	if err := m.SetHidden("abc123", false); err != nil {
+		t.Fatalf("SetHidden failed: %v", err)
+	}
+	meta, _ = m.Load()
+	if len(meta.Hidden) != 0 {
+		t.Errorf("Unhidden commits should be removed, got %v", meta.Hidden)
+	}
+}
+
+func TestManager_SetChangelogText(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "tutugit-test-text-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	m := NewManager(tmpDir)
+	m.Bootstrap()
+
+	if err := m.SetChangelogText("abc123", "Fix saving", "Longer text"); err != nil {
+		t.Fatalf("SetChangelogText failed: %v", err)
+	}
+	meta, _ := m.Load()
+	if meta.Subjects["abc123"] != "Fix saving" || meta.Descriptions["abc123"] != "Longer text" {
+		t.Errorf("Unexpected overrides: %v %v", meta.Subjects, meta.Descriptions)
+	}
+
+	// clearing the subject keeps the description
+	if err := m.SetChangelogText("abc123", "", "Longer text"); err != nil {
+		t.Fatalf("SetChangelogText failed: %v", err)
+	}
+	meta, _ = m.Load()
+	if _, ok := meta.Subjects["abc123"]; ok || meta.Descriptions["abc123"] != "Longer text" {
+		t.Errorf("Unexpected overrides after clearing: %v %v", meta.Subjects, meta.Descriptions)
+	}
+}
+
+func TestManager_RecordRelease(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "tutugit-test-releases-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	m := NewManager(tmpDir)
+	m.Bootstrap()
+
+	day := func(d int) time.Time { return time.Date(2024, 5, d, 0, 0, 0, 0, time.UTC) }
+	records := []RecordedRelease{
+		{Version: "1.0.0", Tag: "v1.0.0", Date: day(1), Commits: []string{"a"}, Impact: "minor"},
+		{Version: "1.1.0", Tag: "v1.1.0", Date: day(9), Commits: []string{"b"}, Impact: "minor", Notes: json.RawMessage(`{"version":"v1.1.0"}`)},
+		{Version: "1.0.0", Tag: "v1.0.0", Date: day(2), Commits: []string{"a", "c"}, Impact: "patch"}, // recorded again
+	}
+	for _, rec := range records {
+		if err := m.RecordRelease(rec); err != nil {
+			t.Fatalf("RecordRelease failed: %v", err)
+		}
+	}
+
+	meta, _ := m.Load()
+	if len(meta.Releases) != 2 || meta.Releases[0].Tag != "v1.1.0" || meta.Releases[1].Impact != "patch" {
+		t.Fatalf("Expected two records, newest first, got %+v", meta.Releases)
+	}
+	var notes struct{ Version string }
+	if err := json.Unmarshal(meta.Releases[0].Notes, &notes); err != nil || notes.Version != "v1.1.0" || !meta.Releases[1].Date.Equal(day(2)) {
+		t.Errorf("Records don't round-trip: %+v", meta.Releases)
+	}
+}
+
+func TestManager_SetUpcomingNotes(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "tutugit-test-notes-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	m := NewManager(tmpDir)
+	m.Bootstrap()
+
+	notes := ReleaseNotes{Intro: "A faster release.", Highlights: []string{"Parallel fetch", "New export"}}
+	if err := m.SetUpcomingNotes(notes); err != nil {
+		t.Fatalf("SetUpcomingNotes failed: %v", err)
+	}
+	meta, _ := m.Load()
+	if meta.Upcoming == nil || meta.Upcoming.Intro != notes.Intro || len(meta.Upcoming.Highlights) != 2 {
+		t.Fatalf("Expected the notes to be stored, got %+v", meta.Upcoming)
+	}
+
+	if err := m.SetUpcomingNotes(ReleaseNotes{}); err != nil {
+		t.Fatalf("SetUpcomingNotes failed: %v", err)
+	}
+	meta, _ = m.Load()
+	if meta.Upcoming != nil {
+		t.Errorf("Expected empty notes to be removed, got %+v", meta.Upcoming)
+	}
+}
+
 func TestManager_CreateWorkspace_Duplicate(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "tutugit-test-dup-ws-*")
 	if err != nil {